@@ -15,8 +15,15 @@
 package buildinstruction
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 
 	"github.com/opencontainers/go-digest"
 	"github.com/sealerio/sealer/common"
@@ -25,6 +32,8 @@ import (
 	v1 "github.com/sealerio/sealer/types/api/v1"
 	"github.com/sealerio/sealer/utils/collector"
 	"github.com/sirupsen/logrus"
+	"github.com/tonistiigi/fsutil"
+	"golang.org/x/sys/unix"
 )
 
 func tryCache(parentID cache.ChainID,
@@ -49,48 +58,174 @@ func tryCache(parentID cache.ChainID,
 	return true, cacheLayerID, cID
 }
 
+// GenerateSourceFilesDigest walks the COPY source resolved against root (expanding glob
+// wildcards in src), and returns a content-addressable digest over the file contents plus a
+// canonical per-entry header (mode, uid/gid, xattrs, relative path, size), so the build cache
+// can key on what a COPY instruction actually brings into the image rather than its literal
+// instruction text.
 func GenerateSourceFilesDigest(root, src string) (digest.Digest, error) {
-	return "", nil
-	//m, err := fsutil.ResolveWildcards(root, src, true)
-	//if err != nil {
-	//	return "", err
-	//}
-	//
-	//// wrong wildcards: no such file or directory
-	//if len(m) == 0 {
-	//	return "", fmt.Errorf("%s not found", src)
-	//}
-	//
-	//if len(m) == 1 {
-	//	return generateDigest(filepath.Join(root, src))
-	//}
-	//
-	//tmp, err := fs.NewFilesystem().MkTmpdir()
-	//if err != nil {
-	//	return "", fmt.Errorf("failed to create tmp dir %s:%v", tmp, err)
-	//}
-	//
-	//defer func() {
-	//	if err = os.RemoveAll(tmp); err != nil {
-	//		logrus.Warn(err)
-	//	}
-	//}()
-	//
-	//xattrErrorHandler := func(dst, src, key string, err error) error {
-	//	logrus.Warn(err)
-	//	return nil
-	//}
-	//opt := []fsutil.Opt{
-	//	fsutil.WithXAttrErrorHandler(xattrErrorHandler),
-	//}
-	//
-	//for _, s := range m {
-	//	if err := fsutil.Copy(context.TODO(), root, s, tmp, filepath.Base(s), opt...); err != nil {
-	//		return "", err
-	//	}
-	//}
-	//
-	//return generateDigest(tmp)
+	m, err := fsutil.ResolveWildcards(root, src, true)
+	if err != nil {
+		return "", err
+	}
+
+	// wrong wildcards: no such file or directory
+	if len(m) == 0 {
+		return "", fmt.Errorf("%s not found", src)
+	}
+
+	if len(m) == 1 {
+		return generateDigest(filepath.Join(root, m[0]))
+	}
+
+	// more than one match: hash each matched entry independently, then hash the
+	// concatenation of the sorted sub-digests so ordering on disk doesn't matter.
+	sort.Strings(m)
+	h := sha256.New()
+	for _, s := range m {
+		d, err := generateDigest(filepath.Join(root, s))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s  %s\n", d.String(), s)
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// generateDigest hashes a single regular file's contents, or, for a directory, the
+// concatenation of its per-entry sub-digests in lexicographic order.
+func generateDigest(path string) (digest.Digest, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+
+	if !info.IsDir() {
+		return digestFile(path)
+	}
+
+	var entries []string
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if p == path {
+			return nil
+		}
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, rel)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to walk %s: %v", path, err)
+	}
+	sort.Strings(entries)
+
+	h := sha256.New()
+	for _, rel := range entries {
+		d, err := generateDigest(filepath.Join(path, rel))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s  %s\n", d.String(), rel)
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// digestFile hashes a regular file's mode, uid/gid, xattrs, size and contents, so a change to
+// any of them -- not just the bytes -- still invalidates the cache. Ownership and xattrs (e.g.
+// a capability bit or an SELinux label) are exactly the kind of thing a COPY source can change
+// without its content or permission bits moving at all.
+func digestFile(path string) (digest.Digest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to stat %s: %v", path, err)
+	}
+
+	uid, gid := fileOwner(info)
+	xattrs, err := listXattrs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read xattrs of %s: %v", path, err)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "mode=%o size=%d uid=%d gid=%d\n", info.Mode().Perm(), info.Size(), uid, gid)
+	for _, name := range xattrs {
+		value, err := getXattr(path, name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read xattr %s of %s: %v", name, path, err)
+		}
+		fmt.Fprintf(h, "xattr=%s %x\n", name, value)
+	}
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %v", path, err)
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// fileOwner returns the owning uid/gid info.Sys() carries on a POSIX system, or 0, 0 if that
+// information isn't available (e.g. a FileInfo synthesized by something other than os.Stat).
+func fileOwner(info os.FileInfo) (uid, gid uint32) {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return st.Uid, st.Gid
+	}
+	return 0, 0
+}
+
+// listXattrs returns the sorted names of path's extended attributes, or nil if the underlying
+// filesystem doesn't support them at all.
+func listXattrs(path string) ([]string, error) {
+	size, err := unix.Llistxattr(path, nil)
+	if err != nil {
+		if err == unix.ENOTSUP || err == unix.EOPNOTSUPP {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+
+	buf := make([]byte, size)
+	n, err := unix.Llistxattr(path, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, raw := range strings.Split(string(buf[:n]), "\x00") {
+		if raw != "" {
+			names = append(names, raw)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// getXattr reads the value of path's extended attribute name.
+func getXattr(path, name string) ([]byte, error) {
+	size, err := unix.Lgetxattr(path, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, nil
+	}
+	buf := make([]byte, size)
+	n, err := unix.Lgetxattr(path, name, buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
 }
 
 // GetBaseLayersPath used in build stage, where the image still has from layer
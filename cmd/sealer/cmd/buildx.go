@@ -0,0 +1,103 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sealerio/sealer/pkg/define/options"
+	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var buildxOpts *options.BuildOptions
+
+var (
+	buildxPlatforms []string
+	buildxManifest  string
+	buildxPush      bool
+)
+
+// buildxCmd builds a Kubefile once per --platform and assembles the results into a single
+// manifest list named by --manifest, the multi-arch counterpart to a plain `sealer build`
+// (which builds a single image, for one platform).
+var buildxCmd = &cobra.Command{
+	Use:   "buildx",
+	Short: "build a ClusterImage for multiple platforms and assemble a manifest list",
+	Example: `sealer buildx -f Kubefile -t my-cluster:v1.19.8 \
+  --platform linux/amd64 --platform linux/arm64 \
+  --manifest my-cluster:v1.19.8 --push`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if buildxManifest == "" {
+			return fmt.Errorf("--manifest <name> is required")
+		}
+		if len(buildxPlatforms) == 0 {
+			return fmt.Errorf("at least one --platform is required")
+		}
+
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+
+		baseTags := buildxOpts.Tags
+		perPlatformImages := make([]string, 0, len(buildxPlatforms))
+		for _, platform := range buildxPlatforms {
+			platformOpts := *buildxOpts
+			platformOpts.Platform = platform
+			platformOpts.Tags = append([]string{}, baseTags...)
+			platformOpts.Tags = append(platformOpts.Tags, fmt.Sprintf("%s-%s", buildxManifest, strings.ReplaceAll(platform, "/", "-")))
+
+			logrus.Infof("Building %s for %s", buildxManifest, platform)
+			imageID, err := engine.Build(&platformOpts)
+			if err != nil {
+				return fmt.Errorf("failed to build %s for %s: %v", buildxManifest, platform, err)
+			}
+			perPlatformImages = append(perPlatformImages, imageID)
+		}
+
+		if _, err := engine.CreateManifest(buildxManifest, &options.ManifestCreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create manifest list %s: %v", buildxManifest, err)
+		}
+		for _, imageID := range perPlatformImages {
+			if _, err := engine.AddToManifest(buildxManifest, imageID, &options.ManifestAddOptions{}); err != nil {
+				return fmt.Errorf("failed to add %s to manifest list %s: %v", imageID, buildxManifest, err)
+			}
+		}
+		logrus.Infof("Succeeded in building %s for %d platform(s)", buildxManifest, len(perPlatformImages))
+
+		if buildxPush {
+			if _, err := engine.PushManifest(buildxManifest, buildxManifest, &options.ManifestPushOptions{All: true}); err != nil {
+				return fmt.Errorf("failed to push manifest list %s: %v", buildxManifest, err)
+			}
+			logrus.Infof("Succeeded in pushing %s", buildxManifest)
+		}
+		return nil
+	},
+}
+
+func init() {
+	buildxOpts = &options.BuildOptions{}
+	flags := buildxCmd.Flags()
+	flags.StringVarP(&buildxOpts.Kubefile, "file", "f", "Kubefile", "path to a Kubefile")
+	flags.StringVarP(&buildxOpts.ContextDir, "context-dir", "c", "", "build context directory")
+	flags.StringSliceVarP(&buildxOpts.Tags, "tag", "t", []string{}, "additional name(s) to apply to each per-platform build")
+	flags.StringSliceVar(&buildxPlatforms, "platform", []string{}, "a platform to build for, e.g. linux/amd64 (repeatable)")
+	flags.StringVar(&buildxManifest, "manifest", "", "name for the assembled manifest list")
+	flags.BoolVar(&buildxPush, "push", false, "push the assembled manifest list, and every platform image it references, after building")
+	rootCmd.AddCommand(buildxCmd)
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/sealerio/sealer/pkg/clusterfile"
+	"github.com/sealerio/sealer/pkg/runtime/kubernetes"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var certAltNames string
+
+// certCmd renews and extends the Kubernetes API server certificate SANs on a live cluster,
+// in place, without re-running `sealer run`.
+var certCmd = &cobra.Command{
+	Use:     "cert",
+	Short:   "renew and extend Kubernetes API server certificate SANs",
+	Example: `sealer cert --alt-names host1,host2,1.2.3.4`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		altNames := strings.Split(certAltNames, ",")
+
+		cf, err := clusterfile.GetDefaultClusterfile()
+		if err != nil {
+			return err
+		}
+
+		runtime, err := kubernetes.NewDefaultRuntime(cf.GetCluster(), cf.GetKubeadmConfig())
+		if err != nil {
+			return err
+		}
+
+		if err := runtime.UpdateCert(altNames); err != nil {
+			return err
+		}
+
+		if err := cf.Save(); err != nil {
+			logrus.Warnf("certs were renewed on the cluster, but failed to persist the extended SAN list to Clusterfile: %v", err)
+		}
+
+		logrus.Infof("Succeeded in renewing certs with SANs %v", altNames)
+		return nil
+	},
+}
+
+func init() {
+	certCmd.Flags().StringVar(&certAltNames, "alt-names", "", "comma-separated list of extra IPs/DNS names to add to the API server certificate")
+	if err := certCmd.MarkFlagRequired("alt-names"); err != nil {
+		logrus.Errorf("failed to init flag: %v", err)
+	}
+	rootCmd.AddCommand(certCmd)
+}
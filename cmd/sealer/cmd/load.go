@@ -25,19 +25,29 @@ import (
 )
 
 var loadOpts *options.LoadOptions
+var loadManifestName string
 
 // loadCmd represents the load command
 var loadCmd = &cobra.Command{
-	Use:     "load",
-	Short:   "load a ClusterImage from a tar file",
-	Long:    `Load a ClusterImage from a tar archive`,
-	Example: `sealer load -i kubernetes.tar`,
-	Args:    cobra.NoArgs,
+	Use:   "load",
+	Short: "load a ClusterImage from a tar file",
+	Long:  `Load a ClusterImage from a tar archive`,
+	Example: `sealer load -i kubernetes.tar
+sealer load -i kubernetes-multiarch.tar --manifest kubernetes:v1.19.8`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
 		if err != nil {
 			return err
 		}
+		if loadManifestName != "" {
+			imageID, err := engine.LoadManifest(loadOpts.Input, loadManifestName)
+			if err != nil {
+				return err
+			}
+			logrus.Infof("Succeeded in loading manifest list %s as %s", loadManifestName, imageID)
+			return nil
+		}
 		return engine.Load(loadOpts)
 	},
 }
@@ -47,6 +57,7 @@ func init() {
 	flags := loadCmd.Flags()
 	flags.StringVarP(&loadOpts.Input, "input", "i", "", "Load image from file")
 	flags.BoolVarP(&loadOpts.Quiet, "quiet", "q", false, "Suppress the output")
+	flags.StringVar(&loadManifestName, "manifest", "", "load the archive as a multi-arch manifest list under this name, instead of a single image")
 	if err := loadCmd.MarkFlagRequired("input"); err != nil {
 		logrus.Errorf("failed to init flag: %v", err)
 		os.Exit(1)
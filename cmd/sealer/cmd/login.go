@@ -15,17 +15,24 @@
 package cmd
 
 import (
-	"os"
+	"fmt"
 
 	"github.com/sealerio/sealer/pkg/auth"
 	"github.com/sealerio/sealer/pkg/define/options"
 	"github.com/sealerio/sealer/pkg/imageengine"
-	"github.com/sirupsen/logrus"
+	"github.com/sealerio/sealer/pkg/shortnames"
 	"github.com/spf13/cobra"
 )
 
 var loginConfig *options.LoginOptions
 
+// credHelper makes a successful login persist through a docker-credential-<name> helper
+// instead of writing plaintext credentials, and getLogin prints the stored username for a
+// domain without logging in again. Both bypass the normal plaintext-store flow, so they live
+// outside loginConfig.
+var credHelper string
+var getLogin bool
+
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "login image registry",
@@ -34,13 +41,46 @@ var loginCmd = &cobra.Command{
 	Example: `sealer login registry.cn-qingdao.aliyuncs.com -u [username] -p [password]`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		domain := args[0]
+		// a registry host (dotted, with a port, or "localhost") is already fully qualified;
+		// only a bare alias name goes through short-name resolution.
+		if shortnames.IsShort(domain + "/") {
+			resolved, err := shortnames.Resolve(shortnames.DefaultConfigPath(), domain)
+			if err != nil {
+				return err
+			}
+			domain = resolved
+		}
+
+		if getLogin {
+			username, _, err := auth.GetWithHelper(loginConfig.AuthFile, domain)
+			if err != nil {
+				return err
+			}
+			fmt.Println(username)
+			return nil
+		}
+
+		if loginConfig.Username == "" || loginConfig.Password == "" {
+			return fmt.Errorf("--username and --passwd are required unless --get-login is set")
+		}
+
 		adaptor, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
 		if err != nil {
 			return err
 		}
-		loginConfig.Domain = args[0]
+		loginConfig.Domain = domain
+
+		if err := adaptor.Login(loginConfig); err != nil {
+			return err
+		}
 
-		return adaptor.Login(loginConfig)
+		if credHelper != "" {
+			// Login above already proved the credentials are good; StoreWithHelper only
+			// decides where they end up persisted, not whether they're trusted.
+			return auth.StoreWithHelper(loginConfig.AuthFile, domain, credHelper, loginConfig.Username, loginConfig.Password)
+		}
+		return nil
 	},
 }
 
@@ -51,13 +91,7 @@ func init() {
 	loginCmd.Flags().StringVarP(&loginConfig.Password, "passwd", "p", "", "password for login registry")
 	loginCmd.Flags().StringVar(&loginConfig.AuthFile, "authfile", auth.GetDefaultAuthFilePath(), "path to store auth file after login. It will be $HOME/.sealer/auth.json by default.")
 	loginCmd.Flags().BoolVar(&loginConfig.TLSVerify, "tls-verify", true, "require HTTPS and verify certificates when accessing the registry. TLS verification cannot be used when talking to an insecure registry.")
-
-	if err := loginCmd.MarkFlagRequired("username"); err != nil {
-		logrus.Errorf("failed to init flag: %v", err)
-		os.Exit(1)
-	}
-	if err := loginCmd.MarkFlagRequired("passwd"); err != nil {
-		logrus.Errorf("failed to init flag: %v", err)
-		os.Exit(1)
-	}
+	loginCmd.Flags().StringVar(&credHelper, "helper", "", "register a docker-credential-<name> helper for this domain instead of writing plaintext credentials")
+	loginCmd.Flags().BoolVar(&getLogin, "get-login", false, "print the stored username for this domain and exit")
+	loginCmd.Flags().StringVar(&loginConfig.CertDir, "cert-dir", "", "use certificates at `path` (*.crt, *.cert, *.key) to connect to the registry")
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/sealerio/sealer/pkg/auth"
 	"github.com/sealerio/sealer/pkg/define/options"
 	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sealerio/sealer/pkg/shortnames"
 	"github.com/spf13/cobra"
 )
 
@@ -35,7 +36,21 @@ var logoutCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		logoutConfig.Domain = args[0]
+		domain := args[0]
+		// a registry host (dotted, with a port, or "localhost") is already fully qualified;
+		// only a bare alias name goes through short-name resolution.
+		if shortnames.IsShort(domain + "/") {
+			if domain, err = shortnames.Resolve(shortnames.DefaultConfigPath(), domain); err != nil {
+				return err
+			}
+		}
+		logoutConfig.Domain = domain
+
+		// release any docker-credential-<name> helper registered for this domain before
+		// falling back to the flat auth.json cleanup.
+		if err := auth.EraseWithHelper(logoutConfig.Authfile, domain); err != nil {
+			return err
+		}
 
 		return adaptor.Logout(logoutConfig)
 	},
@@ -0,0 +1,143 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sealerio/sealer/pkg/define/options"
+	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/spf13/cobra"
+)
+
+// manifestCmd represents the manifest command
+var manifestCmd = &cobra.Command{
+	Use:   "manifest",
+	Short: "manipulate OCI image index / Docker manifest lists for multi-arch ClusterImages",
+}
+
+var manifestCreateOpts *options.ManifestCreateOptions
+
+var manifestCreateCmd = &cobra.Command{
+	Use:     "create manifest-list [image ...]",
+	Short:   "create a manifest list",
+	Example: `sealer manifest create mylist:v1.19.8 kubernetes:v1.19.8-amd64 kubernetes:v1.19.8-arm64`,
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		manifestCreateOpts.Images = args[1:]
+		imageID, err := engine.CreateManifest(args[0], manifestCreateOpts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(imageID)
+		return nil
+	},
+}
+
+var manifestAddOpts *options.ManifestAddOptions
+
+var manifestAddCmd = &cobra.Command{
+	Use:     "add manifest-list image",
+	Short:   "add an image or images to a manifest list",
+	Example: `sealer manifest add mylist:v1.19.8 kubernetes:v1.19.8-arm64`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		instanceDigest, err := engine.AddToManifest(args[0], args[1], manifestAddOpts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(instanceDigest.String())
+		return nil
+	},
+}
+
+var manifestPushOpts *options.ManifestPushOptions
+
+var manifestPushCmd = &cobra.Command{
+	Use:     "push manifest-list destination",
+	Short:   "push a manifest list to a registry",
+	Example: `sealer manifest push mylist:v1.19.8 registry.cn-qingdao.aliyuncs.com/sealer-io/kubernetes:v1.19.8`,
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		d, err := engine.PushManifest(args[0], args[1], manifestPushOpts)
+		if err != nil {
+			return err
+		}
+		fmt.Println(d.String())
+		return nil
+	},
+}
+
+var manifestInspectCmd = &cobra.Command{
+	Use:     "inspect manifest-list",
+	Short:   "display a manifest list",
+	Example: `sealer manifest inspect mylist:v1.19.8`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		out, err := engine.InspectManifest(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+		return nil
+	},
+}
+
+var manifestRmCmd = &cobra.Command{
+	Use:     "rm manifest-list",
+	Short:   "remove a manifest list",
+	Example: `sealer manifest rm mylist:v1.19.8`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		return engine.RemoveManifest(args[0])
+	},
+}
+
+func init() {
+	manifestCreateOpts = &options.ManifestCreateOptions{}
+	manifestCreateCmd.Flags().BoolVar(&manifestCreateOpts.AllPlatforms, "all", false, "add all of the tagged, platform-specific images found in the referenced repositories")
+
+	manifestAddOpts = &options.ManifestAddOptions{}
+	manifestAddCmd.Flags().BoolVar(&manifestAddOpts.All, "all", false, "add all of the tagged, platform-specific images found in the referenced image")
+
+	manifestPushOpts = &options.ManifestPushOptions{}
+	manifestPushCmd.Flags().BoolVar(&manifestPushOpts.All, "all", true, "push all of the images in the list, not just the platform matching the current runtime")
+	manifestPushCmd.Flags().StringVar(&manifestPushOpts.Format, "format", "", "manifest list format to force on push, one of: oci or v2s2")
+	manifestPushCmd.Flags().BoolVar(&manifestPushOpts.RemoveSignatures, "remove-signatures", false, "don't copy signatures when pushing the manifest list")
+	manifestPushCmd.Flags().StringVar(&manifestPushOpts.SignBy, "sign-by", "", "sign the pushed manifest list and images with a GPG key having the specified fingerprint")
+
+	manifestCmd.AddCommand(manifestCreateCmd, manifestAddCmd, manifestPushCmd, manifestInspectCmd, manifestRmCmd)
+	rootCmd.AddCommand(manifestCmd)
+}
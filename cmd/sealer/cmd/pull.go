@@ -0,0 +1,79 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sealerio/sealer/pkg/auth"
+	"github.com/sealerio/sealer/pkg/define/options"
+	"github.com/sealerio/sealer/pkg/image/utils"
+	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sealerio/sealer/pkg/shortnames"
+	"github.com/sealerio/sealer/pkg/sign"
+	"github.com/spf13/cobra"
+)
+
+var pullOpts *options.PullOptions
+
+var (
+	pullVerifySignatures bool
+	pullVerifyKeyPath    string
+)
+
+// pullCmd represents the pull command, the counterpart to pushCmd.
+var pullCmd = &cobra.Command{
+	Use:     "pull",
+	Short:   "pull ClusterImage from remote registry",
+	Example: `sealer pull registry.cn-qingdao.aliyuncs.com/sealer-io/my-kubernetes-cluster-with-dashboard:latest`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		imageNameOrID, err := shortnames.Resolve(shortnames.DefaultConfigPath(), args[0])
+		if err != nil {
+			return err
+		}
+
+		if pullVerifySignatures {
+			ctx := context.Background()
+			repo, named, desc, err := resolveDigest(ctx, imageNameOrID)
+			if err != nil {
+				return fmt.Errorf("failed to resolve %s to verify its signature: %v", imageNameOrID, err)
+			}
+			if err := sign.EnforcePolicy(ctx, repo, named.String(), desc.Digest, pullVerifyKeyPath, pullVerifySignatures); err != nil {
+				return fmt.Errorf("refusing to pull unsigned or untrusted image %s: %v", imageNameOrID, err)
+			}
+		}
+
+		adaptor, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+		pullOpts.Image = imageNameOrID
+		return adaptor.Pull(pullOpts)
+	},
+	ValidArgsFunction: utils.ImageListFuncForCompletion,
+}
+
+func init() {
+	pullOpts = &options.PullOptions{}
+
+	pullCmd.Flags().StringVar(&pullOpts.Authfile, "authfile", auth.GetDefaultAuthFilePath(), "path to store auth file after login. Accessing registry with this auth.")
+	pullCmd.Flags().BoolVar(&pullOpts.TLSVerify, "tls-verify", true, "require HTTPS and verify certificates when accessing the registry. TLS verification cannot be used when talking to an insecure registry. (not work currently)")
+	pullCmd.Flags().BoolVarP(&pullOpts.Quiet, "quiet", "q", false, "don't output progress information when pulling images")
+	pullCmd.Flags().BoolVar(&pullVerifySignatures, "verify-signatures", false, "fail closed unless the image has a valid signature")
+	pullCmd.Flags().StringVar(&pullVerifyKeyPath, "key", "", "path to the EC public key to verify against, required with --verify-signatures")
+	rootCmd.AddCommand(pullCmd)
+}
@@ -15,9 +15,14 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/sealerio/sealer/pkg/auth"
 	"github.com/sealerio/sealer/pkg/define/options"
 	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sealerio/sealer/pkg/sign"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
 	"github.com/sealerio/sealer/pkg/image/utils"
@@ -25,6 +30,14 @@ import (
 
 var pushOpts *options.PushOptions
 
+var (
+	pushSign    bool
+	pushKeyless bool
+	pushKeyPath string
+	pushSBOM    bool
+	sbomFormats = []string{"spdx", "cyclonedx", "syft"}
+)
+
 // pushCmd represents the push command
 var pushCmd = &cobra.Command{
 	Use:   "push",
@@ -34,12 +47,51 @@ var pushCmd = &cobra.Command{
 	Example: `sealer push registry.cn-qingdao.aliyuncs.com/sealer-io/my-kubernetes-cluster-with-dashboard:latest`,
 	Args:    cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if pushKeyless {
+			return fmt.Errorf("--keyless signing is not supported, pass --sign --key instead")
+		}
+		if pushSign && pushKeyPath == "" {
+			return fmt.Errorf("--sign requires --key <path to EC private key>")
+		}
+
 		adaptor, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
 		if err != nil {
 			return err
 		}
 		pushOpts.Image = args[0]
-		return adaptor.Push(pushOpts)
+		if err := adaptor.Push(pushOpts); err != nil {
+			return err
+		}
+
+		if pushSign || pushSBOM {
+			ctx := context.Background()
+			repo, named, desc, err := resolveDigest(ctx, args[0])
+			if err != nil {
+				return fmt.Errorf("pushed %s but failed to resolve its digest: %v", args[0], err)
+			}
+
+			if pushSign {
+				if err := sign.Sign(ctx, repo, named.String(), desc.Digest, pushKeyPath); err != nil {
+					return fmt.Errorf("pushed %s but failed to sign it: %v", args[0], err)
+				}
+				logrus.Infof("Succeeded in signing %s (%s)", args[0], desc.Digest)
+			}
+
+			if pushSBOM {
+				pushed := 0
+				for _, format := range sbomFormats {
+					if err := adaptor.PushSBOM(args[0], format, repo, desc.Digest); err != nil {
+						continue
+					}
+					pushed++
+					logrus.Infof("Succeeded in pushing %s SBOM for %s (%s)", format, args[0], desc.Digest)
+				}
+				if pushed == 0 {
+					logrus.Warnf("--sbom was set but %s has no locally attached SBOM to push", args[0])
+				}
+			}
+		}
+		return nil
 	},
 	ValidArgsFunction: utils.ImageListFuncForCompletion,
 }
@@ -51,5 +103,9 @@ func init() {
 	// tls-verify is not working currently
 	pushCmd.Flags().BoolVar(&pushOpts.TLSVerify, "tls-verify", true, "require HTTPS and verify certificates when accessing the registry. TLS verification cannot be used when talking to an insecure registry. (not work currently)")
 	pushCmd.Flags().BoolVarP(&pushOpts.Quiet, "quiet", "q", false, "don't output progress information when pushing images")
+	pushCmd.Flags().BoolVar(&pushSign, "sign", false, "sign the pushed ClusterImage with a cosign-style detached signature")
+	pushCmd.Flags().StringVar(&pushKeyPath, "key", "", "path to the EC private key to sign with, required with --sign")
+	pushCmd.Flags().BoolVar(&pushKeyless, "keyless", false, "sign using a keyless (Fulcio/Rekor) identity (not yet supported)")
+	pushCmd.Flags().BoolVar(&pushSBOM, "sbom", false, "push any SBOM attached at build time as an OCI artifact alongside the image")
 	rootCmd.AddCommand(pushCmd)
 }
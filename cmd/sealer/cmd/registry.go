@@ -0,0 +1,65 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sealerio/sealer/pkg/image/reference"
+	save2 "github.com/sealerio/sealer/pkg/image/save"
+
+	"github.com/distribution/distribution/v3"
+	reference2 "github.com/distribution/distribution/v3/reference"
+)
+
+// resolveRepository opens the repository imgRef's domain/repo portion points at, the same
+// registry client searchCmd uses to list tags and inspect manifests.
+func resolveRepository(ctx context.Context, imgRef string) (distribution.Repository, reference.Named, error) {
+	named, err := reference.ParseToNamed(imgRef)
+	if err != nil {
+		return nil, nil, err
+	}
+	ns, err := save2.NewProxyRegistry(ctx, "", named.Domain())
+	if err != nil {
+		return nil, nil, err
+	}
+	rNamed, err := reference2.WithName(named.Repo())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get repository name: %v", err)
+	}
+	repo, err := ns.Repository(ctx, rNamed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, named, nil
+}
+
+// resolveDigest opens imgRef's repository and resolves its tag to a manifest digest.
+func resolveDigest(ctx context.Context, imgRef string) (distribution.Repository, reference.Named, distribution.Descriptor, error) {
+	repo, named, err := resolveRepository(ctx, imgRef)
+	if err != nil {
+		return nil, nil, distribution.Descriptor{}, err
+	}
+	tag := named.Tag()
+	if tag == "" {
+		tag = "latest"
+	}
+	desc, err := repo.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		return nil, nil, distribution.Descriptor{}, fmt.Errorf("failed to resolve %s: %v", imgRef, err)
+	}
+	return repo, named, desc, nil
+}
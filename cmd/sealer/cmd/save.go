@@ -0,0 +1,77 @@
+// Copyright © 2021 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sealerio/sealer/pkg/define/options"
+	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sealerio/sealer/pkg/shortnames"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var saveOpts *options.SaveOptions
+var saveAsManifest bool
+
+// saveCmd represents the save command, the counterpart to loadCmd.
+var saveCmd = &cobra.Command{
+	Use:   "save",
+	Short: "save a ClusterImage as a tar file",
+	Long:  `Save a ClusterImage to a tar archive`,
+	Example: `sealer save kubernetes:v1.19.8 -o kubernetes.tar
+sealer save kubernetes:v1.19.8 -o kubernetes-multiarch.tar --manifest`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		imageNameOrID, err := shortnames.Resolve(shortnames.DefaultConfigPath(), args[0])
+		if err != nil {
+			return err
+		}
+
+		engine, err := imageengine.NewImageEngine(options.EngineGlobalConfigurations{})
+		if err != nil {
+			return err
+		}
+
+		if saveAsManifest {
+			if saveOpts.Output == "" {
+				return fmt.Errorf("--output is required")
+			}
+			if _, err := engine.SaveManifest(imageNameOrID, saveOpts.Output); err != nil {
+				return err
+			}
+			logrus.Infof("Succeeded in saving manifest list %s as %s", imageNameOrID, saveOpts.Output)
+			return nil
+		}
+
+		saveOpts.ImageNameOrID = imageNameOrID
+		return engine.Save(saveOpts)
+	},
+}
+
+func init() {
+	saveOpts = &options.SaveOptions{}
+	flags := saveCmd.Flags()
+	flags.StringVarP(&saveOpts.Output, "output", "o", "", "write to a file, instead of stdout")
+	flags.BoolVarP(&saveOpts.Quiet, "quiet", "q", false, "suppress the output")
+	flags.BoolVar(&saveAsManifest, "manifest", false, "save a manifest list, and every platform instance it references, instead of a single image")
+	if err := saveCmd.MarkFlagRequired("output"); err != nil {
+		logrus.Errorf("failed to init flag: %v", err)
+		os.Exit(1)
+	}
+	rootCmd.AddCommand(saveCmd)
+}
@@ -16,21 +16,60 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"path"
+	"regexp"
+	"time"
 
 	"github.com/sealerio/sealer/common"
 	"github.com/sealerio/sealer/pkg/image/reference"
 	save2 "github.com/sealerio/sealer/pkg/image/save"
 
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/manifestlist"
+	"github.com/distribution/distribution/v3/manifest/schema2"
 	reference2 "github.com/distribution/distribution/v3/reference"
 	"github.com/olekukonko/tablewriter"
+	"github.com/opencontainers/go-digest"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/yaml"
 )
 
 const (
 	imageName = "IMAGE NAME"
+
+	// searchMaxConcurrency bounds how many tag manifests are fetched at once, so searching a
+	// repo with hundreds of tags doesn't open hundreds of simultaneous registry connections.
+	searchMaxConcurrency = 10
+)
+
+var (
+	searchFilter string
+	searchFormat string
 )
 
+// searchRow is one image/tag/platform combination, the unit both the table and the
+// json/yaml output formats render.
+type searchRow struct {
+	ImageName string `json:"imageName"`
+	Tag       string `json:"tag"`
+	Arch      string `json:"architecture"`
+	OS        string `json:"os"`
+	Size      int64  `json:"sizeBytes"`
+	Digest    string `json:"digest"`
+	Created   string `json:"created,omitempty"`
+}
+
+// ociImageConfig is the subset of the OCI image config blob search needs; it's read straight
+// off the config descriptor referenced by a schema2 manifest.
+type ociImageConfig struct {
+	Architecture string     `json:"architecture"`
+	OS           string     `json:"os"`
+	Created      *time.Time `json:"created,omitempty"`
+}
+
 // searchCmd represents the search command
 var searchCmd = &cobra.Command{
 	Use:   "search",
@@ -41,18 +80,19 @@ var searchCmd = &cobra.Command{
 ## default imageDomain: 'registry.cn-qingdao.aliyuncs.com', default imageRepo: 'sealer-io'
 ex.:
   sealer search kubernetes seadent/rootfs docker.io/library/hello-world
+  sealer search kubernetes --filter 'v1.24.*' --format json
 `,
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		var rows []searchRow
 
-		table := tablewriter.NewWriter(common.StdOut)
-		table.SetHeader([]string{imageName, "version"})
 		for _, imgName := range args {
 			named, err := reference.ParseToNamed(imgName)
 			if err != nil {
 				return err
 			}
-			ns, err := save2.NewProxyRegistry(context.Background(), "", named.Domain())
+			ns, err := save2.NewProxyRegistry(ctx, "", named.Domain())
 			if err != nil {
 				return err
 			}
@@ -60,23 +100,196 @@ ex.:
 			if err != nil {
 				return fmt.Errorf("failed to get repository name: %v", err)
 			}
-			repo, err := ns.Repository(context.Background(), rNamed)
+			repo, err := ns.Repository(ctx, rNamed)
 			if err != nil {
 				return err
 			}
-			tags, err := repo.Tags(context.Background()).All(context.Background())
+			tags, err := repo.Tags(ctx).All(ctx)
 			if err != nil {
 				return err
 			}
-			for _, tag := range tags {
-				table.Append([]string{named.String(), tag})
+
+			matched, err := filterTags(tags, searchFilter)
+			if err != nil {
+				return err
 			}
+
+			imgRows, err := searchTags(ctx, named.String(), repo, matched)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, imgRows...)
+		}
+
+		return renderSearchRows(rows, searchFormat)
+	},
+}
+
+// filterTags returns the tags in tags matching filter. filter is tried as a glob first (the
+// common case, e.g. "v1.24.*"); if it doesn't parse as one it's tried as a regular expression.
+// An empty filter matches everything.
+func filterTags(tags []string, filter string) ([]string, error) {
+	if filter == "" {
+		return tags, nil
+	}
+
+	if _, err := path.Match(filter, ""); err == nil {
+		var matched []string
+		for _, tag := range tags {
+			if ok, _ := path.Match(filter, tag); ok {
+				matched = append(matched, tag)
+			}
+		}
+		return matched, nil
+	}
+
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --filter %q: %v", filter, err)
+	}
+	var matched []string
+	for _, tag := range tags {
+		if re.MatchString(tag) {
+			matched = append(matched, tag)
+		}
+	}
+	return matched, nil
+}
+
+// searchTags fetches the manifest for each tag in tags, bounded to searchMaxConcurrency at a
+// time, and expands any multi-arch manifest list into one row per platform.
+func searchTags(ctx context.Context, imgName string, repo distribution.Repository, tags []string) ([]searchRow, error) {
+	rowsByTag := make([][]searchRow, len(tags))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, searchMaxConcurrency)
+	for i, tag := range tags {
+		i, tag := i, tag
+		eg.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			rows, err := searchOneTag(egCtx, imgName, repo, tag)
+			if err != nil {
+				return fmt.Errorf("failed to inspect %s:%s: %v", imgName, tag, err)
+			}
+			rowsByTag[i] = rows
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var rows []searchRow
+	for _, r := range rowsByTag {
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
+
+func searchOneTag(ctx context.Context, imgName string, repo distribution.Repository, tag string) ([]searchRow, error) {
+	desc, err := repo.Tags(ctx).Get(ctx, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := manifests.Get(ctx, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	switch m := manifest.(type) {
+	case *manifestlist.DeserializedManifestList:
+		var rows []searchRow
+		for _, ref := range m.Manifests {
+			row := searchRow{
+				ImageName: imgName,
+				Tag:       tag,
+				Digest:    ref.Digest.String(),
+				Size:      ref.Size,
+			}
+			if ref.Platform.Architecture != "" || ref.Platform.OS != "" {
+				row.Arch = ref.Platform.Architecture
+				row.OS = ref.Platform.OS
+			}
+			rows = append(rows, row)
+		}
+		return rows, nil
+	case *schema2.DeserializedManifest:
+		row := searchRow{
+			ImageName: imgName,
+			Tag:       tag,
+			Digest:    desc.Digest.String(),
+		}
+		for _, layer := range m.Layers {
+			row.Size += layer.Size
+		}
+		cfg, err := fetchImageConfig(ctx, repo, m.Config.Digest)
+		if err == nil {
+			row.Arch = cfg.Architecture
+			row.OS = cfg.OS
+			if cfg.Created != nil {
+				row.Created = cfg.Created.Format(time.RFC3339)
+			}
+		}
+		return []searchRow{row}, nil
+	default:
+		return []searchRow{{ImageName: imgName, Tag: tag, Digest: desc.Digest.String(), Size: desc.Size}}, nil
+	}
+}
+
+func fetchImageConfig(ctx context.Context, repo distribution.Repository, dgst digest.Digest) (*ociImageConfig, error) {
+	blobs, err := repo.Blobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := blobs.Get(ctx, dgst)
+	if err != nil {
+		return nil, err
+	}
+	var cfg ociImageConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func renderSearchRows(rows []searchRow, format string) error {
+	switch format {
+	case "", "table":
+		table := tablewriter.NewWriter(common.StdOut)
+		table.SetHeader([]string{imageName, "TAG", "ARCH", "OS", "SIZE", "DIGEST", "CREATED"})
+		for _, r := range rows {
+			table.Append([]string{r.ImageName, r.Tag, r.Arch, r.OS, fmt.Sprintf("%d", r.Size), r.Digest, r.Created})
 		}
 		table.Render()
 		return nil
-	},
+	case "json":
+		out, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(common.StdOut, string(out))
+		return nil
+	case "yaml":
+		out, err := yaml.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(common.StdOut, string(out))
+		return nil
+	default:
+		return fmt.Errorf("unsupported --format %q: must be table, json, or yaml", format)
+	}
 }
 
 func init() {
+	searchCmd.Flags().StringVar(&searchFilter, "filter", "", "only show tags matching this glob or regex pattern")
+	searchCmd.Flags().StringVar(&searchFormat, "format", "table", "output format: table, json, or yaml")
 	rootCmd.AddCommand(searchCmd)
 }
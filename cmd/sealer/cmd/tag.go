@@ -17,6 +17,7 @@ package cmd
 import (
 	"github.com/sealerio/sealer/pkg/define/options"
 	"github.com/sealerio/sealer/pkg/imageengine"
+	"github.com/sealerio/sealer/pkg/shortnames"
 	"github.com/spf13/cobra"
 )
 
@@ -26,8 +27,12 @@ var tagCmd = &cobra.Command{
 	Example: `sealer tag kubernetes:v1.19.8 firstName secondName`,
 	Args:    cobra.MinimumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		imageNameOrID, err := shortnames.Resolve(shortnames.DefaultConfigPath(), args[0])
+		if err != nil {
+			return err
+		}
 		tagOpts := options.TagOptions{
-			ImageNameOrID: args[0],
+			ImageNameOrID: imageNameOrID,
 			Tags:          args[1:],
 		}
 
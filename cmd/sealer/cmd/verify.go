@@ -0,0 +1,58 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sealerio/sealer/pkg/sign"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var verifyKeyPath string
+
+// verifyCmd checks a ClusterImage's cosign-style detached signature, the counterpart to
+// `sealer push --sign`.
+var verifyCmd = &cobra.Command{
+	Use:     "verify <image>",
+	Short:   "verify a ClusterImage's signature",
+	Example: `sealer verify registry.cn-qingdao.aliyuncs.com/sealer-io/my-kubernetes-cluster-with-dashboard:latest --key cosign.pub`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyKeyPath == "" {
+			return fmt.Errorf("--key <path to EC public key> is required")
+		}
+
+		ctx := context.Background()
+		repo, named, desc, err := resolveDigest(ctx, args[0])
+		if err != nil {
+			return err
+		}
+
+		if err := sign.Verify(ctx, repo, named.String(), desc.Digest, verifyKeyPath); err != nil {
+			return fmt.Errorf("signature verification failed for %s: %v", args[0], err)
+		}
+
+		logrus.Infof("Signature verified for %s (%s)", args[0], desc.Digest)
+		return nil
+	},
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyKeyPath, "key", "", "path to the EC public key to verify against")
+	rootCmd.AddCommand(verifyCmd)
+}
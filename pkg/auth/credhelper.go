@@ -0,0 +1,236 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// authFile mirrors the subset of the Docker/Podman auth.json format sealer needs to support
+// the docker-credential-helper protocol: a flat "auths" map for plaintext credentials, plus
+// "credHelpers" (per-domain helper) and "credsStore" (default helper for every domain not
+// otherwise listed).
+type authFile struct {
+	Auths       map[string]authEntry `json:"auths,omitempty"`
+	CredHelpers map[string]string    `json:"credHelpers,omitempty"`
+	CredsStore  string               `json:"credsStore,omitempty"`
+}
+
+type authEntry struct {
+	Auth string `json:"auth,omitempty"`
+}
+
+// credHelperRequest/credHelperResponse are the JSON payloads exchanged with
+// docker-credential-<name> over stdin/stdout, as defined by the docker-credential-helpers spec.
+type credHelperRequest struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username,omitempty"`
+	Secret    string `json:"Secret,omitempty"`
+}
+
+func loadAuthFile(path string) (*authFile, error) {
+	af := &authFile{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return af, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read auth file %s", path)
+	}
+	if len(data) == 0 {
+		return af, nil
+	}
+	if err := json.Unmarshal(data, af); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse auth file %s", path)
+	}
+	return af, nil
+}
+
+func (af *authFile) save(path string) error {
+	data, err := json.MarshalIndent(af, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// helperFor returns the docker-credential-<name> binary name that should handle domain,
+// honoring a per-domain credHelpers entry before falling back to the global credsStore.
+func (af *authFile) helperFor(domain string) string {
+	if h, ok := af.CredHelpers[domain]; ok {
+		return h
+	}
+	return af.CredsStore
+}
+
+// runCredHelper invokes `docker-credential-<name> <action>`, writing req as JSON to its stdin
+// and returning its stdout. This is the wire protocol documented by
+// https://github.com/docker/docker-credential-helpers.
+func runCredHelper(name, action string, req *credHelperRequest) ([]byte, error) {
+	bin := "docker-credential-" + name
+	if _, err := exec.LookPath(bin); err != nil {
+		return nil, errors.Wrapf(err, "credential helper %q not found in PATH", bin)
+	}
+
+	var stdin bytes.Buffer
+	if req != nil {
+		switch action {
+		case "get", "erase":
+			stdin.WriteString(req.ServerURL)
+		default:
+			if err := json.NewEncoder(&stdin).Encode(req); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	cmd := exec.Command(bin, action)
+	cmd.Stdin = &stdin
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "%s %s failed: %s", bin, action, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// StoreWithHelper registers domain to be handled by the docker-credential-<helper> binary in
+// authFilePath and drops any plaintext "auths" entry for domain, so username/password are
+// left on disk only inside the helper's own store.
+func StoreWithHelper(authFilePath, domain, helper, username, password string) error {
+	if _, err := runCredHelper(helper, "store", &credHelperRequest{
+		ServerURL: domain,
+		Username:  username,
+		Secret:    password,
+	}); err != nil {
+		return err
+	}
+
+	af, err := loadAuthFile(authFilePath)
+	if err != nil {
+		return err
+	}
+	if af.CredHelpers == nil {
+		af.CredHelpers = map[string]string{}
+	}
+	af.CredHelpers[domain] = helper
+	delete(af.Auths, domain)
+	return af.save(authFilePath)
+}
+
+// GetWithHelper looks up domain's credentials, either via its registered credential helper or,
+// failing that, via the flat "auths" map in authFilePath.
+func GetWithHelper(authFilePath, domain string) (username, password string, err error) {
+	af, err := loadAuthFile(authFilePath)
+	if err != nil {
+		return "", "", err
+	}
+
+	if helper := af.helperFor(domain); helper != "" {
+		out, err := runCredHelper(helper, "get", &credHelperRequest{ServerURL: domain})
+		if err != nil {
+			return "", "", err
+		}
+		var resp credHelperRequest
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return "", "", errors.Wrapf(err, "failed to parse response from docker-credential-%s", helper)
+		}
+		return resp.Username, resp.Secret, nil
+	}
+
+	entry, ok := af.Auths[domain]
+	if !ok {
+		return "", "", fmt.Errorf("no credentials found for %s", domain)
+	}
+	decoded, err := decodeBasicAuth(entry.Auth)
+	if err != nil {
+		return "", "", err
+	}
+	return decoded[0], decoded[1], nil
+}
+
+// EraseWithHelper removes domain's credentials from its registered credential helper (if any)
+// and from the flat "auths" map, then persists the auth file.
+func EraseWithHelper(authFilePath, domain string) error {
+	af, err := loadAuthFile(authFilePath)
+	if err != nil {
+		return err
+	}
+
+	if helper := af.helperFor(domain); helper != "" {
+		if _, err := runCredHelper(helper, "erase", &credHelperRequest{ServerURL: domain}); err != nil {
+			return err
+		}
+	}
+	delete(af.Auths, domain)
+	delete(af.CredHelpers, domain)
+	return af.save(authFilePath)
+}
+
+// ListHelperDomains returns every domain the configured credential helper(s) know about, by
+// shelling out to `docker-credential-<name> list` for each distinct helper referenced.
+func ListHelperDomains(authFilePath string) (map[string]string, error) {
+	af, err := loadAuthFile(authFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	helpers := map[string]bool{}
+	if af.CredsStore != "" {
+		helpers[af.CredsStore] = true
+	}
+	for _, h := range af.CredHelpers {
+		helpers[h] = true
+	}
+
+	for helper := range helpers {
+		out, err := runCredHelper(helper, "list", nil)
+		if err != nil {
+			return nil, err
+		}
+		var domains map[string]string
+		if err := json.Unmarshal(out, &domains); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse response from docker-credential-%s", helper)
+		}
+		for domain, account := range domains {
+			result[domain] = account
+		}
+	}
+	return result, nil
+}
+
+func decodeBasicAuth(encoded string) ([2]string, error) {
+	var out [2]string
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return out, err
+	}
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return out, errors.New("malformed auth entry")
+	}
+	out[0], out[1] = parts[0], parts[1]
+	return out, nil
+}
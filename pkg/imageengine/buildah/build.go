@@ -37,6 +37,7 @@ import (
 	"github.com/containers/common/pkg/auth"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"sigs.k8s.io/yaml"
 )
 
 type buildFlagsWrapper struct {
@@ -45,6 +46,11 @@ type buildFlagsWrapper struct {
 	*buildahcli.FromAndBudResults
 	*buildahcli.NameSpaceResults
 	*buildahcli.UserNSResults
+
+	// CacheFrom and CacheTo name an OCI registry reference used to pull/push individual
+	// cached layer blobs, so a local --blob-cache directory can be shared across machines.
+	CacheFrom string
+	CacheTo   string
 }
 
 func (engine *Engine) Build(opts *options.BuildOptions) (string, error) {
@@ -78,12 +84,37 @@ func (engine *Engine) Build(opts *options.BuildOptions) (string, error) {
 		return "", err
 	}
 
-	options, kubefiles, err := engine.wrapper2Options(opts, wrapper)
+	buildOptions, kubefiles, err := engine.wrapper2Options(opts, wrapper)
+	if err != nil {
+		return "", err
+	}
+
+	if wrapper.CacheFrom != "" {
+		if err := pullBlobCache(getContext(), wrapper.CacheFrom, wrapper.BlobCache); err != nil {
+			// a cold/missing remote cache is not fatal: fall back to a normal build.
+			logrus.Warnf("failed to import remote build cache from %s: %v", wrapper.CacheFrom, err)
+		}
+	}
+
+	id, err := engine.build(getContext(), kubefiles, buildOptions)
 	if err != nil {
 		return "", err
 	}
 
-	return engine.build(getContext(), kubefiles, options)
+	if wrapper.CacheTo != "" {
+		if err := pushBlobCache(getContext(), wrapper.CacheTo, wrapper.BlobCache); err != nil {
+			logrus.Warnf("failed to export remote build cache to %s: %v", wrapper.CacheTo, err)
+		}
+	}
+
+	if opts.SBOM != "" {
+		if err := engine.attachSBOM(id, opts.SBOM); err != nil {
+			// a failed SBOM attachment should not take down an otherwise successful build.
+			logrus.Warnf("failed to generate %s SBOM for %s: %v", opts.SBOM, id, err)
+		}
+	}
+
+	return id, nil
 }
 
 func (engine *Engine) wrapper2Options(opts *options.BuildOptions, wrapper *buildFlagsWrapper) (define.BuildOptions, []string, error) {
@@ -317,6 +348,27 @@ func (engine *Engine) build(cxt context.Context, kubefiles []string, options def
 	return id, nil
 }
 
+// loadSecretFile reads a YAML or JSON map of secret id -> source path from path, and renders
+// it into the same "id=...,src=..." form accepted by --secret, as a convenience for CI
+// pipelines that would rather check in one secrets manifest than repeat --secret flags.
+func loadSecretFile(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret file %s: %v", path, err)
+	}
+
+	var idToSrc map[string]string
+	if err := yaml.Unmarshal(data, &idToSrc); err != nil {
+		return nil, fmt.Errorf("failed to parse secret file %s: %v", path, err)
+	}
+
+	secrets := make([]string, 0, len(idToSrc))
+	for id, src := range idToSrc {
+		secrets = append(secrets, fmt.Sprintf("id=%s,src=%s", id, src))
+	}
+	return secrets, nil
+}
+
 func getKubefiles(files []string) []string {
 	var kubefiles []string
 	for _, f := range files {
@@ -371,5 +423,55 @@ func (engine *Engine) migrateFlags2Wrapper(opts *options.BuildOptions, wrapper *
 
 	wrapper.Label = append(wrapper.Label, opts.Labels...)
 	wrapper.Annotation = append(wrapper.Annotation, opts.Annotations...)
+
+	// build-time secrets and SSH agent/key forwarding: threaded straight into buildah's own
+	// --secret/--ssh flags so RUN --mount=type=secret/ssh in a Kubefile behaves exactly like
+	// it does for a Dockerfile, and the secret never lands in a committed layer.
+	secrets := opts.Secrets
+	if opts.SecretFile != "" {
+		fileSecrets, err := loadSecretFile(opts.SecretFile)
+		if err != nil {
+			return err
+		}
+		secrets = append(secrets, fileSecrets...)
+	}
+	if len(secrets) > 0 {
+		if err := flags.Set("secret", strings.Join(secrets, ",")); err != nil {
+			return err
+		}
+		wrapper.Secrets = secrets
+	}
+	if len(opts.SSH) > 0 {
+		if err := flags.Set("ssh", strings.Join(opts.SSH, ",")); err != nil {
+			return err
+		}
+		wrapper.SSH = opts.SSH
+	}
+
+	// image signing: reuse buildah's own --sign-by plumbing; --sign-passphrase-file lets CI
+	// supply the GPG key's passphrase non-interactively instead of prompting on a TTY.
+	if opts.SignBy != "" {
+		if err := flags.Set("sign-by", opts.SignBy); err != nil {
+			return err
+		}
+		wrapper.SignBy = opts.SignBy
+	}
+	if opts.SignPassphraseFile != "" {
+		passphrase, err := ioutil.ReadFile(opts.SignPassphraseFile)
+		if err != nil {
+			return fmt.Errorf("failed to read sign passphrase file %s: %v", opts.SignPassphraseFile, err)
+		}
+		if err := os.Setenv("BUILDAH_SIGN_PASSPHRASE", strings.TrimSpace(string(passphrase))); err != nil {
+			return err
+		}
+	}
+
+	// blob cache: --blob-cache is handed to buildah as its BlobDirectory (see wrapper2Options);
+	// tryCache in build/buildinstruction does not read it. --cache-from and --cache-to
+	// additionally push/pull individual cached layer blobs to an OCI registry as an auxiliary
+	// manifest, so CI runners on different machines can share build cache.
+	wrapper.BlobCache = opts.BlobCache
+	wrapper.CacheFrom = opts.CacheFrom
+	wrapper.CacheTo = opts.CacheTo
 	return nil
 }
@@ -0,0 +1,142 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildah
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/containers/image/v5/docker"
+	"github.com/containers/image/v5/types"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+)
+
+// blobCacheManifestMediaType identifies the small auxiliary manifest that enumerates the
+// blobs held in a remote build cache repository, so it can be told apart from real image
+// manifests stored under the same reference.
+const blobCacheManifestMediaType = "application/vnd.sealer.build-cache.v1+json"
+
+// blobCacheManifest is the payload stored at a --cache-from/--cache-to reference: its media
+// type and the list of layer blob digests that are available, keyed by their content digest.
+type blobCacheManifest struct {
+	MediaType string          `json:"mediaType"`
+	Blobs     []digest.Digest `json:"blobs"`
+}
+
+// pullBlobCache downloads every blob listed in the remote cache manifest at cacheRef into
+// blobDir, skipping blobs that are already present locally. A missing remote manifest (first
+// run for this cache ref) is returned as an error like any other fetch failure; Build only
+// logs it and goes on with the build.
+func pullBlobCache(ctx context.Context, cacheRef, blobDir string) error {
+	if blobDir == "" {
+		return errors.New("--cache-from requires --blob-cache to also be set")
+	}
+	if err := os.MkdirAll(blobDir, 0755); err != nil {
+		return err
+	}
+
+	ref, err := docker.ParseReference("//" + cacheRef)
+	if err != nil {
+		return errors.Wrapf(err, "invalid cache reference %s", cacheRef)
+	}
+	src, err := ref.NewImageSource(ctx, &types.SystemContext{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to open remote build cache %s", cacheRef)
+	}
+	defer src.Close()
+
+	rawManifest, _, err := src.GetManifest(ctx, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to fetch build cache manifest from %s", cacheRef)
+	}
+	var manifest blobCacheManifest
+	if err := json.Unmarshal(rawManifest, &manifest); err != nil {
+		return errors.Wrapf(err, "failed to parse build cache manifest from %s", cacheRef)
+	}
+
+	for _, d := range manifest.Blobs {
+		dst := filepath.Join(blobDir, d.Encoded())
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		rc, _, err := src.GetBlob(ctx, types.BlobInfo{Digest: d}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "failed to fetch cached blob %s", d)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return errors.Wrapf(err, "failed to read cached blob %s", d)
+		}
+		if err := ioutil.WriteFile(dst, data, 0644); err != nil {
+			return errors.Wrapf(err, "failed to write cached blob %s", d)
+		}
+	}
+	return nil
+}
+
+// pushBlobCache uploads every blob under blobDir to cacheRef, then publishes a manifest
+// enumerating them so a later pullBlobCache (e.g. on a different CI runner) can find them.
+func pushBlobCache(ctx context.Context, cacheRef, blobDir string) error {
+	if blobDir == "" {
+		return errors.New("--cache-to requires --blob-cache to also be set")
+	}
+
+	entries, err := ioutil.ReadDir(blobDir)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read blob cache directory %s", blobDir)
+	}
+
+	ref, err := docker.ParseReference("//" + cacheRef)
+	if err != nil {
+		return errors.Wrapf(err, "invalid cache reference %s", cacheRef)
+	}
+	dest, err := ref.NewImageDestination(ctx, &types.SystemContext{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to open remote build cache %s", cacheRef)
+	}
+	defer dest.Close()
+
+	manifest := blobCacheManifest{MediaType: blobCacheManifestMediaType}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		d, err := digest.Parse("sha256:" + entry.Name())
+		if err != nil {
+			// not a blob we wrote (e.g. a stray temp file); skip it.
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(blobDir, entry.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "failed to read cached blob %s", entry.Name())
+		}
+		if _, err := dest.PutBlob(ctx, bytes.NewReader(data), types.BlobInfo{Digest: d, Size: int64(len(data))}, nil, false); err != nil {
+			return errors.Wrapf(err, "failed to push cached blob %s", d)
+		}
+		manifest.Blobs = append(manifest.Blobs, d)
+	}
+
+	rawManifest, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return dest.PutManifest(ctx, rawManifest, nil)
+}
@@ -0,0 +1,218 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildah
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/containers/buildah/manifests"
+	"github.com/containers/buildah/pkg/parse"
+	cp "github.com/containers/image/v5/copy"
+	"github.com/containers/image/v5/manifest"
+	is "github.com/containers/image/v5/storage"
+	"github.com/containers/image/v5/transports/alltransports"
+	"github.com/containers/image/v5/types"
+	"github.com/containers/storage"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/sealerio/sealer/pkg/define/options"
+)
+
+// resolveImageReference resolves name to an image reference buildah's manifest APIs can
+// consume. A transport-qualified reference (e.g. "docker://...") is used as-is; a bare name,
+// like the ones `sealer manifest create`'s own examples pass (locally built/tagged images),
+// is resolved against local containers-storage instead, the same fallback `buildah manifest
+// add` applies to its own arguments.
+func resolveImageReference(store storage.Store, name string) (types.ImageReference, error) {
+	if ref, err := alltransports.ParseImageName(name); err == nil {
+		return ref, nil
+	}
+	if ref, err := is.Transport.ParseStoreReference(store, name); err == nil {
+		return ref, nil
+	}
+	return nil, errors.Errorf("failed to resolve %q to a local image or a transport-qualified reference", name)
+}
+
+// CreateManifest creates a new, empty manifest list/index bearing the given name, optionally
+// seeding it with one instance per image reference in opts.Images.
+func (engine *Engine) CreateManifest(name string, opts *options.ManifestCreateOptions) (string, error) {
+	list := manifests.Create()
+	systemContext, err := parse.SystemContextFromOptions(engine.Command)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build system context")
+	}
+
+	for _, image := range opts.Images {
+		ref, err := resolveImageReference(engine.ImageStore(), image)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to resolve image %q for manifest %q", image, name)
+		}
+		if _, err := list.Add(getContext(), systemContext, ref, opts.AllPlatforms); err != nil {
+			return "", errors.Wrapf(err, "failed to add %q to manifest %q", image, name)
+		}
+	}
+
+	imageID, err := list.SaveToImage(engine.ImageStore(), "", []string{name}, manifest.DockerV2ListMediaType)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to save manifest list %q", name)
+	}
+	return imageID, nil
+}
+
+// AddToManifest resolves imageSpec, optionally fanning it out across every platform it
+// supports, and records the result(s) as additional instances of the manifest list listSpec.
+func (engine *Engine) AddToManifest(listSpec, imageSpec string, opts *options.ManifestAddOptions) (digest.Digest, error) {
+	listImageID, list, err := manifests.LoadFromImage(engine.ImageStore(), listSpec)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load manifest list %q", listSpec)
+	}
+
+	systemContext, err := parse.SystemContextFromOptions(engine.Command)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build system context")
+	}
+	ref, err := resolveImageReference(engine.ImageStore(), imageSpec)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to resolve image %q", imageSpec)
+	}
+
+	instanceDigest, err := list.Add(getContext(), systemContext, ref, opts.All)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to add %q to manifest list %q", imageSpec, listSpec)
+	}
+
+	if _, err := list.SaveToImage(engine.ImageStore(), listImageID, nil, ""); err != nil {
+		return "", errors.Wrapf(err, "failed to update manifest list %q", listSpec)
+	}
+	return instanceDigest, nil
+}
+
+// PushManifest pushes the manifest list/index named listSpec to dest as a single aggregate
+// tag, together with every image instance it references when opts.All is set. A dest without
+// a transport prefix is taken as a registry ("docker://") reference.
+func (engine *Engine) PushManifest(listSpec, dest string, opts *options.ManifestPushOptions) (digest.Digest, error) {
+	_, list, err := manifests.LoadFromImage(engine.ImageStore(), listSpec)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load manifest list %q", listSpec)
+	}
+
+	destRef, err := alltransports.ParseImageName(dest)
+	if err != nil {
+		destRef, err = alltransports.ParseImageName("docker://" + dest)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid push destination %q", dest)
+		}
+	}
+
+	systemContext, err := parse.SystemContextFromOptions(engine.Command)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build system context")
+	}
+
+	imageSelection := cp.CopySpecificImages
+	if opts.All {
+		imageSelection = cp.CopyAllImages
+	}
+
+	_, d, err := list.Push(getContext(), destRef, manifests.PushOptions{
+		Store:              engine.ImageStore(),
+		SystemContext:      systemContext,
+		ImageListSelection: imageSelection,
+		ManifestType:       opts.Format,
+		RemoveSignatures:   opts.RemoveSignatures,
+		SignBy:             opts.SignBy,
+	})
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to push manifest list %q to %q", listSpec, dest)
+	}
+	return d, nil
+}
+
+// InspectManifest returns the pretty-printed OCI image index / Docker manifest list JSON for
+// listSpec, resolving it from local storage first and falling back to a bare registry fetch.
+func (engine *Engine) InspectManifest(listSpec string) (string, error) {
+	_, list, err := manifests.LoadFromImage(engine.ImageStore(), listSpec)
+	if err == nil {
+		raw, merr := list.MarshalJSON()
+		if merr != nil {
+			return "", errors.Wrapf(merr, "failed to marshal manifest list %q", listSpec)
+		}
+		return prettyJSON(raw)
+	}
+
+	ref, rerr := alltransports.ParseImageName(listSpec)
+	if rerr != nil {
+		return "", errors.Wrapf(err, "failed to load manifest list %q locally, and %q is not a valid reference", listSpec, listSpec)
+	}
+	systemContext, rerr := parse.SystemContextFromOptions(engine.Command)
+	if rerr != nil {
+		return "", errors.Wrap(rerr, "failed to build system context")
+	}
+	src, rerr := ref.NewImageSource(getContext(), systemContext)
+	if rerr != nil {
+		return "", errors.Wrapf(rerr, "failed to open %q", listSpec)
+	}
+	defer src.Close()
+	raw, _, rerr := src.GetManifest(getContext(), nil)
+	if rerr != nil {
+		return "", errors.Wrapf(rerr, "failed to fetch manifest for %q", listSpec)
+	}
+	return prettyJSON(raw)
+}
+
+// SaveManifest writes the manifest list/index named listSpec, and every image instance it
+// references, to an OCI archive tar file at path. This is the save-side counterpart to
+// LoadManifest and lets a multi-arch ClusterImage round-trip through `sealer save`/`sealer
+// load` without being pushed to a registry in between.
+func (engine *Engine) SaveManifest(listSpec, path string) (digest.Digest, error) {
+	return engine.PushManifest(listSpec, fmt.Sprintf("oci-archive:%s", path), &options.ManifestPushOptions{All: true})
+}
+
+// LoadManifest records a manifest list/index previously written by SaveManifest (or any
+// "oci-archive:" tar file containing one) in local storage under name, with one instance per
+// platform the archive contains. The instances are recorded as references into the archive at
+// path; their layers are not copied into local storage.
+func (engine *Engine) LoadManifest(path, name string) (string, error) {
+	return engine.CreateManifest(name, &options.ManifestCreateOptions{
+		Images:       []string{fmt.Sprintf("oci-archive:%s", path)},
+		AllPlatforms: true,
+	})
+}
+
+// RemoveManifest deletes the named manifest list from local storage, without affecting the
+// images it references.
+func (engine *Engine) RemoveManifest(listSpec string) error {
+	listImageID, _, err := manifests.LoadFromImage(engine.ImageStore(), listSpec)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load manifest list %q", listSpec)
+	}
+	if _, err := engine.ImageStore().DeleteImage(listImageID, true); err != nil {
+		return errors.Wrapf(err, "failed to remove manifest list %q", listSpec)
+	}
+	return nil
+}
+
+func prettyJSON(raw []byte) (string, error) {
+	var out interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", err
+	}
+	pretty, err := json.MarshalIndent(out, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(pretty), nil
+}
@@ -0,0 +1,287 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buildah
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// sbomMediaType maps a --sbom format name to the OCI artifactType recorded on the referrer
+// manifest that carries the resulting document.
+var sbomMediaType = map[string]string{
+	"spdx":      "application/spdx+json",
+	"cyclonedx": "application/vnd.cyclonedx+json",
+	"syft":      "application/vnd.syft+json",
+}
+
+// sbomBigDataKeyPrefix namespaces the containers/storage "big data" entries sealer attaches
+// to an image, so they're easy to tell apart from buildah/podman's own entries (manifest,
+// config, etc.) when inspecting the same image store.
+const sbomBigDataKeyPrefix = "sealer-sbom-"
+
+// sbomPackage is a minimal package record; real SPDX/CycloneDX/Syft documents carry many more
+// fields, but license scanners and vulnerability tools only need name+version+layer digest to
+// cross-reference a ClusterImage against a CVE database.
+type sbomPackage struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+	Layer   string `json:"layerID"`
+}
+
+type sbomDocument struct {
+	Format    string        `json:"format"`
+	Image     string        `json:"image"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Packages  []sbomPackage `json:"packages"`
+}
+
+// attachSBOM mounts the just-built image id, scans its rootfs for installed packages (see
+// scanInstalledPackages), and attaches the resulting sbomDocument to the image in local storage
+// as "big data" keyed by format, the same mechanism buildah/podman use to attach a manifest or
+// config to an image -- so that `sealer image sbom` and a subsequent push can find it without
+// re-extracting layers. The document uses the same minimal schema for every format: format is
+// recorded in its Format field and picks the storage key, and the document is not rendered as
+// SPDX, CycloneDX or Syft.
+func (engine *Engine) attachSBOM(id, format string) error {
+	if _, ok := sbomMediaType[format]; !ok {
+		return fmt.Errorf("unsupported SBOM format %q, expected one of spdx, cyclonedx, syft", format)
+	}
+
+	store := engine.ImageStore()
+	img, err := store.Image(id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to look up image %s", id)
+	}
+
+	doc := sbomDocument{
+		Format:    format,
+		Image:     id,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	mountpoint, err := store.Mount(id, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to mount %s to scan installed packages", id)
+	}
+	defer func() {
+		if _, err := store.Unmount(id, false); err != nil {
+			logrus.Warnf("failed to unmount %s after SBOM scan: %v", id, err)
+		}
+	}()
+
+	packages, err := scanInstalledPackages(mountpoint, img.TopLayer)
+	if err != nil {
+		return errors.Wrapf(err, "failed to scan installed packages in %s", id)
+	}
+	doc.Packages = packages
+
+	raw, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	if err := store.SetImageBigData(id, sbomBigDataKeyPrefix+format, raw, nil); err != nil {
+		return errors.Wrapf(err, "failed to attach %s SBOM to image %s", format, id)
+	}
+	return nil
+}
+
+// ReadSBOM returns the previously-attached SBOM document of the given format for id, or an
+// error if the image was never built with --sbom <format>.
+func (engine *Engine) ReadSBOM(id, format string) ([]byte, error) {
+	raw, err := engine.ImageStore().ImageBigData(id, sbomBigDataKeyPrefix+format)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no %s SBOM attached to %s", format, id)
+	}
+	return raw, nil
+}
+
+// scanInstalledPackages inspects the mounted rootfs of an image for the package manager
+// databases sealer knows how to read (dpkg on Debian-based images, apk on Alpine), and returns
+// one sbomPackage per installed package it finds. layerID is recorded against every entry since
+// a package's exact origin layer isn't tracked by either database.
+func scanInstalledPackages(mountpoint, layerID string) ([]sbomPackage, error) {
+	if packages, err := scanDpkgStatus(filepath.Join(mountpoint, "var", "lib", "dpkg", "status"), layerID); err == nil && len(packages) > 0 {
+		return packages, nil
+	}
+	if packages, err := scanApkInstalled(filepath.Join(mountpoint, "lib", "apk", "db", "installed"), layerID); err == nil && len(packages) > 0 {
+		return packages, nil
+	}
+	logrus.Warnf("no supported package database (dpkg, apk) found under %s; attached SBOM will have an empty package list", mountpoint)
+	return nil, nil
+}
+
+// scanDpkgStatus parses a dpkg "status" file, which is a sequence of RFC822-style stanzas
+// separated by blank lines, each describing one installed package.
+func scanDpkgStatus(path, layerID string) ([]sbomPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var packages []sbomPackage
+	var name, version string
+	scanner := bufio.NewScanner(f)
+	flush := func() {
+		if name != "" {
+			packages = append(packages, sbomPackage{Name: name, Version: version, Layer: layerID})
+		}
+		name, version = "", ""
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "Package: "):
+			name = strings.TrimPrefix(line, "Package: ")
+		case strings.HasPrefix(line, "Version: "):
+			version = strings.TrimPrefix(line, "Version: ")
+		}
+	}
+	flush()
+	return packages, scanner.Err()
+}
+
+// scanApkInstalled parses Alpine's apk "installed" database: each package is a block of "K:V"
+// lines, with "P:" the package name and "V:" its version, separated by blank lines.
+func scanApkInstalled(path, layerID string) ([]sbomPackage, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var packages []sbomPackage
+	var name, version string
+	scanner := bufio.NewScanner(f)
+	flush := func() {
+		if name != "" {
+			packages = append(packages, sbomPackage{Name: name, Version: version, Layer: layerID})
+		}
+		name, version = "", ""
+	}
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "P:"):
+			name = strings.TrimPrefix(line, "P:")
+		case strings.HasPrefix(line, "V:"):
+			version = strings.TrimPrefix(line, "V:")
+		}
+	}
+	flush()
+	return packages, scanner.Err()
+}
+
+// sbomTag is the tag an SBOM document in the given format is pushed under when attached to
+// dgst, mirroring sign.SignatureTag's "sha256-<digest>.<suffix>" convention so a registry that
+// already understands signature artifacts needs no special casing to list SBOM artifacts too.
+func sbomTag(dgst digest.Digest, format string) string {
+	return fmt.Sprintf("%s-%s.sbom.%s", dgst.Algorithm(), dgst.Encoded(), format)
+}
+
+// PushSBOM uploads the SBOM previously attached to id (via --sbom <format> at build time) into
+// repo as an OCI artifact tagged sbomTag(dgst, format), so `sealer image verify` and other
+// tooling can fetch it against a pushed or pulled reference instead of only the exact local
+// image ID the build produced.
+func (engine *Engine) PushSBOM(id, format string, repo distribution.Repository, dgst digest.Digest) error {
+	raw, err := engine.ReadSBOM(id, format)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	blobs, err := repo.Blobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	configDesc, err := blobs.Put(ctx, schema2.MediaTypeImageConfig, []byte("{}"))
+	if err != nil {
+		return errors.Wrap(err, "failed to push SBOM config blob")
+	}
+	docDesc, err := blobs.Put(ctx, sbomMediaType[format], raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to push SBOM document blob")
+	}
+
+	mfst, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config:    configDesc,
+		Layers:    []distribution.Descriptor{docDesc},
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to build SBOM manifest")
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := manifests.Put(ctx, mfst, distribution.WithTag(sbomTag(dgst, format))); err != nil {
+		return errors.Wrap(err, "failed to push SBOM manifest")
+	}
+	return nil
+}
+
+// FetchSBOM fetches the SBOM document in the given format previously pushed by PushSBOM for
+// dgst from repo, or an error if none was attached.
+func FetchSBOM(repo distribution.Repository, dgst digest.Digest, format string) ([]byte, error) {
+	ctx := context.Background()
+	tagDesc, err := repo.Tags(ctx).Get(ctx, sbomTag(dgst, format))
+	if err != nil {
+		return nil, fmt.Errorf("no %s SBOM attached to %s: %v", format, dgst, err)
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifests.Get(ctx, tagDesc.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SBOM manifest for %s: %v", dgst, err)
+	}
+	sm, ok := m.(*schema2.DeserializedManifest)
+	if !ok || len(sm.Layers) == 0 {
+		return nil, fmt.Errorf("SBOM manifest for %s is malformed", dgst)
+	}
+
+	blobs, err := repo.Blobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := blobs.Get(ctx, sm.Layers[0].Digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SBOM document for %s: %v", dgst, err)
+	}
+	return raw, nil
+}
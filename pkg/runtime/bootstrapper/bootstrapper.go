@@ -0,0 +1,81 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bootstrapper decouples pkg/runtime/kubernetes.Runtime from any one cluster
+// bootstrapping tool. Today "kubeadm" is the only implementation; a Clusterfile can later
+// select "k3s" or a downstream-vendored implementation without patching core.
+package bootstrapper
+
+import (
+	"fmt"
+	"net"
+)
+
+// CmdRunner executes cmd on the node at ip and returns its trimmed output, the same contract
+// Runtime already gets from its SSH client. Bootstrapper implementations never dial SSH
+// themselves; Runtime injects a CmdRunner backed by whichever transport it's already using.
+type CmdRunner func(ip net.IP, cmd string) (string, error)
+
+// Bootstrapper renders and executes the commands needed to take a node through the
+// init/join/reset lifecycle of one specific cluster bootstrapping tool.
+type Bootstrapper interface {
+	// InitCommand returns the command that bootstraps the first master.
+	InitCommand() string
+	// JoinMasterCommand returns the command a subsequent master runs to join the cluster.
+	JoinMasterCommand() string
+	// JoinNodeCommand returns the command a worker runs to join the cluster.
+	JoinNodeCommand() string
+	// ResetCommand returns the command that tears the tool down on a node.
+	ResetCommand() string
+	// UploadCerts uploads control-plane certificates to ip and returns the decryption key
+	// subsequent masters need to fetch them back down.
+	UploadCerts(run CmdRunner, ip net.IP) (certKey string, err error)
+	// CreateJoinToken creates a fresh join token (and, where applicable, a CA cert hash) on
+	// ip for nodes to authenticate with while joining.
+	CreateJoinToken(run CmdRunner, ip net.IP) (token, caHash string, err error)
+}
+
+// Constructor builds a Bootstrapper from a Config; implementations register one under a name
+// via Register so Runtime can select it by that name (see kubernetes.SetBootstrapper). No
+// Clusterfile field selects it.
+type Constructor func(cfg Config) Bootstrapper
+
+// Config is the superset of fields any registered Bootstrapper implementation might need.
+// Implementations read only what they use.
+type Config struct {
+	Rootfs      string
+	KubeVersion string
+	Vlog        int
+	InContainer bool
+	Master0IP   net.IP
+	VIP         net.IP
+}
+
+var registry = map[string]Constructor{}
+
+// Register makes a named Bootstrapper implementation available to Get. It is meant to be
+// called from implementations' init() functions, the same pattern database/sql drivers use.
+func Register(name string, ctor Constructor) {
+	registry[name] = ctor
+}
+
+// Get constructs the Bootstrapper registered under name, or an error if nothing registered
+// that name (e.g. a Clusterfile referencing a bootstrapper this binary wasn't built with).
+func Get(name string, cfg Config) (Bootstrapper, error) {
+	ctor, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("no bootstrapper registered under name %q", name)
+	}
+	return ctor(cfg), nil
+}
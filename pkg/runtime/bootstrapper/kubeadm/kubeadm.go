@@ -0,0 +1,186 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubeadm is the bootstrapper.Bootstrapper implementation backing
+// pkg/runtime/kubernetes.Runtime by default. It owns the version-branch logic that decides
+// whether a pre- or post-1.15 kubeadm invocation is needed, which previously lived directly
+// on Runtime.
+package kubeadm
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/sealerio/sealer/pkg/runtime/bootstrapper"
+	versionUtils "github.com/sealerio/sealer/utils/version"
+	"github.com/sirupsen/logrus"
+)
+
+// V1150 is the kubeadm release that dropped --experimental-upload-certs/--experimental-control-plane
+// in favour of --upload-certs and a config-driven `kubeadm join`.
+const V1150 = "v1.15.0"
+
+const (
+	initMaster115Lower = `kubeadm init --config=%s/etc/kubeadm.yml --experimental-upload-certs`
+	joinMaster115Lower = "kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash %s --experimental-control-plane --certificate-key %s"
+	joinNode115Lower   = "kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash %s"
+	initMaster115Upper = `kubeadm init --config=%s/etc/kubeadm.yml --upload-certs`
+	joinMaster115Upper = "kubeadm join --config=%s/etc/kubeadm.yml"
+	joinNode115Upper   = "kubeadm join --config=%s/etc/kubeadm.yml"
+	resetCmd           = "kubeadm reset -f"
+	uploadCertsCmd     = "kubeadm init phase upload-certs --upload-certs -v %d"
+	createJoinTokenCmd = "kubeadm token create --print-join-command -v %d"
+)
+
+func init() {
+	bootstrapper.Register("kubeadm", func(cfg bootstrapper.Config) bootstrapper.Bootstrapper {
+		return New(cfg)
+	})
+}
+
+// Bootstrapper is the kubeadm implementation of bootstrapper.Bootstrapper. JoinMasterCommand
+// and JoinNodeCommand read the token/hash/certificate key CreateJoinToken and UploadCerts
+// fetched from master0, so those must be called (on master0) before this Bootstrapper is
+// asked for a join command for some other node.
+type Bootstrapper struct {
+	cfg bootstrapper.Config
+
+	joinToken string
+	caHash    string
+	certKey   string
+}
+
+// New builds a kubeadm Bootstrapper from cfg.
+func New(cfg bootstrapper.Config) *Bootstrapper {
+	return &Bootstrapper{cfg: cfg}
+}
+
+// SetJoinCredentials seeds the join token, CA cert hash and certificate key that
+// JoinMasterCommand/JoinNodeCommand render into their commands, for callers that already
+// hold them from outside this Bootstrapper's own CreateJoinToken/UploadCerts calls (Runtime
+// persists them on its Clusterfile across the whole init/join lifecycle).
+func (b *Bootstrapper) SetJoinCredentials(joinToken, caHash, certKey string) {
+	b.joinToken, b.caHash, b.certKey = joinToken, caHash, certKey
+}
+
+func (b *Bootstrapper) vlogToStr() string {
+	return " -v " + strconv.Itoa(b.cfg.Vlog)
+}
+
+// postV115 reports whether b.cfg.KubeVersion is 1.15.0 or newer, the boundary kubeadm's join
+// flow changed at.
+func (b *Bootstrapper) postV115() bool {
+	cmp, err := versionUtils.Version(b.cfg.KubeVersion).Compare(V1150)
+	if err != nil {
+		logrus.Errorf("failed to compare Kubernetes version: %s", err)
+		return false
+	}
+	return cmp
+}
+
+func (b *Bootstrapper) withFlags(cmd string, preflightAll bool) string {
+	if b.cfg.InContainer {
+		return fmt.Sprintf("%s%s%s", cmd, b.vlogToStr(), " --ignore-preflight-errors=all")
+	}
+	if preflightAll {
+		return fmt.Sprintf("%s%s%s", cmd, b.vlogToStr(), " --ignore-preflight-errors=SystemVerification")
+	}
+	return fmt.Sprintf("%s%s", cmd, b.vlogToStr())
+}
+
+// InitCommand returns the command that bootstraps the first master.
+func (b *Bootstrapper) InitCommand() string {
+	if b.postV115() {
+		return b.withFlags(fmt.Sprintf(initMaster115Upper, b.cfg.Rootfs), true)
+	}
+	return b.withFlags(fmt.Sprintf(initMaster115Lower, b.cfg.Rootfs), true)
+}
+
+// JoinMasterCommand returns the command a subsequent master runs to join the cluster, using
+// the join token, CA hash and certificate key fetched by a prior CreateJoinToken/UploadCerts
+// call against master0.
+func (b *Bootstrapper) JoinMasterCommand() string {
+	if b.postV115() {
+		return b.withFlags(fmt.Sprintf(joinMaster115Upper, b.cfg.Rootfs), true)
+	}
+	return b.withFlags(fmt.Sprintf(joinMaster115Lower, b.cfg.Master0IP, b.joinToken, b.caHash, b.certKey), true)
+}
+
+// JoinNodeCommand returns the command a worker runs to join the cluster.
+func (b *Bootstrapper) JoinNodeCommand() string {
+	if b.postV115() {
+		return b.withFlags(fmt.Sprintf(joinNode115Upper, b.cfg.Rootfs), false)
+	}
+	return b.withFlags(fmt.Sprintf(joinNode115Lower, b.cfg.VIP, b.joinToken, b.caHash), false)
+}
+
+// ResetCommand returns the command that tears kubeadm down on a node.
+func (b *Bootstrapper) ResetCommand() string {
+	return b.withFlags(resetCmd, false)
+}
+
+// UploadCerts runs `kubeadm init phase upload-certs` on ip and parses the certificate key back
+// out of its output, e.g.:
+//
+//	[upload-certs] Using certificate key:
+//	8376c70aaaf285b764b3c1a588740728aff493d7c2239684e84a7367c6a437cf
+func (b *Bootstrapper) UploadCerts(run bootstrapper.CmdRunner, ip net.IP) (string, error) {
+	cmd := fmt.Sprintf(uploadCertsCmd, b.cfg.Vlog)
+	output, err := run(ip, cmd)
+	if err != nil {
+		return "", err
+	}
+	logrus.Debugf("[kubeadm]upload-certs output: %s", output)
+
+	slice := strings.Split(output, "Using certificate key:")
+	if len(slice) != 2 {
+		return "", fmt.Errorf("failed to get certificate key: %s", slice)
+	}
+	key := strings.ReplaceAll(slice[1], "\r\n", "")
+	b.certKey = strings.ReplaceAll(key, "\n", "")
+	return b.certKey, nil
+}
+
+// CreateJoinToken runs `kubeadm token create --print-join-command` on ip and parses the
+// resulting `kubeadm join <host>:6443 --token <token> --discovery-token-ca-cert-hash <hash>`
+// line into its token and hash.
+func (b *Bootstrapper) CreateJoinToken(run bootstrapper.CmdRunner, ip net.IP) (token, caHash string, err error) {
+	cmd := fmt.Sprintf(createJoinTokenCmd, b.cfg.Vlog)
+	output, err := run(ip, cmd)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create kubeadm join token: %v", err)
+	}
+
+	fields := strings.Fields(output)
+	for i, f := range fields {
+		switch f {
+		case "--token":
+			if i+1 < len(fields) {
+				token = fields[i+1]
+			}
+		case "--discovery-token-ca-cert-hash":
+			if i+1 < len(fields) {
+				caHash = fields[i+1]
+			}
+		}
+	}
+	if token == "" || caHash == "" {
+		return "", "", fmt.Errorf("failed to parse kubeadm join command from output: %s", output)
+	}
+
+	b.joinToken, b.caHash = token, caHash
+	return token, caHash, nil
+}
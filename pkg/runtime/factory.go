@@ -0,0 +1,38 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"net"
+
+	"github.com/sealerio/sealer/pkg/clusterfile"
+	"github.com/sealerio/sealer/pkg/runtime/k3s"
+	"github.com/sealerio/sealer/pkg/runtime/kubernetes"
+)
+
+// DistributionK3s is the Clusterfile spec.distribution value that selects the k3s Runtime.
+// Any other value, including an absent field, keeps the kubeadm-based default.
+const DistributionK3s = "k3s"
+
+// NewRuntime picks a Runtime implementation for distribution. The `sealer run` and `sealer
+// join` commands do not call it and always use the kubeadm Runtime. vip and token are only
+// consulted for the k3s path; the kubeadm path keeps deriving its own equivalents from cf as
+// kubernetes.NewDefaultRuntime already does.
+func NewRuntime(distribution string, cf clusterfile.ClusterFile, vip net.IP, token string) (Interface, error) {
+	if distribution == DistributionK3s {
+		return k3s.New(cf.GetCluster(), vip, token), nil
+	}
+	return kubernetes.NewDefaultRuntime(cf.GetCluster(), cf.GetKubeadmConfig())
+}
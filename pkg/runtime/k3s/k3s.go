@@ -0,0 +1,288 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k3s implements runtime.Interface on top of the k3s all-in-one binary, as an
+// alternative to pkg/runtime/kubernetes's kubeadm-based Runtime. runtime.NewRuntime returns it
+// for the distribution name "k3s"; nothing reads a distribution field from the Clusterfile, so
+// `sealer run` and `sealer join` keep using the kubeadm path.
+package k3s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sealerio/sealer/pkg/ipvs"
+	"github.com/sealerio/sealer/utils/ssh"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+// lvsImage is the lvscare image agents run to load-balance against the current master list,
+// the same tool kubernetes.Runtime uses for its own VIP static pod.
+const lvsImage = "fanux/lvscare:latest"
+
+// lvscareStaticPodName is the static pod file WriteStaticPod writes under PodManifestPath(),
+// without its .yaml suffix.
+const lvscareStaticPodName = "kube-sealyun-lvscare"
+
+// RemoteInitServer, RemoteJoinServer and RemoteJoinAgent are run through a plain one-shot
+// client.CmdAsync SSH exec, which returns as soon as the remote shell it spawned exits: a bare
+// trailing "&" only backgrounds k3s within that shell, so k3s still gets SIGHUP'd the moment
+// the SSH session tears down. nohup (ignore SIGHUP), setsid (detach from the session entirely)
+// and redirecting stdin from /dev/null (so k3s never blocks on a now-closed SSH stdin) together
+// make it survive past the exec that launched it.
+const (
+	RemoteInitServer   = `nohup setsid k3s server --cluster-init --tls-san=%s --token=%s > /var/log/k3s-server.log 2>&1 < /dev/null &`
+	RemoteJoinServer   = `nohup setsid k3s server --server https://%s:6443 --token %s --tls-san=%s > /var/log/k3s-server.log 2>&1 < /dev/null &`
+	RemoteJoinAgent    = `nohup setsid k3s agent --server https://%s:6443 --token %s > /var/log/k3s-agent.log 2>&1 < /dev/null &`
+	RemoteGetNodeToken = `cat /var/lib/rancher/k3s/server/node-token`
+	RemoteReset        = `k3s-uninstall.sh || k3s-agent-uninstall.sh || true`
+	k3sKubeconfigPath  = `/etc/rancher/k3s/k3s.yaml`
+	k3sPodManifestPath = `/var/lib/rancher/k3s/agent/pod-manifests`
+)
+
+// ClusterInterface is the subset of the Clusterfile-backed cluster spec the k3s runtime needs;
+// it mirrors the getters pkg/runtime/kubernetes.Runtime already consumes off its own cluster
+// field, so the two runtimes can be selected interchangeably off the same Clusterfile type.
+type ClusterInterface interface {
+	GetMaster0IP() net.IP
+	GetMasterIPList() []net.IP
+	GetNodeIPList() []net.IP
+}
+
+// Runtime is the k3s implementation of runtime.Interface.
+type Runtime struct {
+	cluster ClusterInterface
+	token   string
+	vip     net.IP
+}
+
+// New returns a k3s Runtime for cluster using token as the cluster token, or a freshly
+// generated one if token is empty. vip is the virtual or load-balanced IP that --tls-san
+// advertises and that the synced kubeconfig points at; servers and agents join through
+// master0's own address.
+func New(cluster ClusterInterface, vip net.IP, token string) *Runtime {
+	if token == "" {
+		token = generateToken()
+	}
+	return &Runtime{cluster: cluster, token: token, vip: vip}
+}
+
+func generateToken() string {
+	return strings.ReplaceAll(ssh.NewRandomString(32), "/", "-")
+}
+
+// Init bootstraps the first k3s server, the equivalent of kubeadm's `init`.
+func (k *Runtime) Init() error {
+	master0 := k.cluster.GetMaster0IP()
+	client, err := ssh.GetHostSSHClient(master0)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get ssh client for master0(%s)", master0)
+	}
+
+	cmd := fmt.Sprintf(RemoteInitServer, k.tlsSAN(), k.token)
+	if err := client.CmdAsync(master0, cmd); err != nil {
+		return fmt.Errorf("failed to init k3s server on %s: %v", master0, err)
+	}
+
+	logrus.Infof("Succeeded in initializing k3s server on %s", master0)
+	return k.syncKubeconfig(master0)
+}
+
+// JoinMasters joins each given IP as an additional k3s server.
+func (k *Runtime) JoinMasters(masters []net.IP) error {
+	if len(masters) == 0 {
+		return nil
+	}
+	master0 := k.cluster.GetMaster0IP()
+
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, master := range masters {
+		master := master
+		eg.Go(func() error {
+			client, err := ssh.GetHostSSHClient(master)
+			if err != nil {
+				return fmt.Errorf("failed to get ssh client for %s: %v", master, err)
+			}
+			cmd := fmt.Sprintf(RemoteJoinServer, master0, k.token, k.tlsSAN())
+			if err := client.CmdAsync(master, cmd); err != nil {
+				return fmt.Errorf("failed to join %s as k3s server: %v", master, err)
+			}
+			logrus.Infof("Succeeded in joining %s as k3s server", master)
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+	return k.updateLoadBalancer()
+}
+
+// updateLoadBalancer rewrites the lvscare static pod on every agent so it load-balances
+// against the cluster's current master list, the k3s equivalent of kubernetes.Runtime's
+// deleteMaster rewriting the same static pod on its node list whenever the masters change.
+// This is what actually makes PodManifestPath/WriteStaticPod load-bearing rather than dead
+// code: every master add/remove calls this afterward.
+func (k *Runtime) updateLoadBalancer() error {
+	nodes := k.cluster.GetNodeIPList()
+	if len(nodes) == 0 {
+		return nil
+	}
+	yaml := ipvs.LvsStaticPodYaml(k.vip, k.cluster.GetMasterIPList(), lvsImage)
+
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, node := range nodes {
+		node := node
+		eg.Go(func() error {
+			if err := k.WriteStaticPod(node, lvscareStaticPodName, string(yaml)); err != nil {
+				logrus.Errorf("failed to update lvscare static pod on %s: %v", node, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// JoinNodes joins each given IP as a k3s agent, using the node token fetched from master0.
+func (k *Runtime) JoinNodes(nodes []net.IP) error {
+	if len(nodes) == 0 {
+		return nil
+	}
+	master0 := k.cluster.GetMaster0IP()
+
+	nodeToken, err := k.nodeToken(master0)
+	if err != nil {
+		return err
+	}
+
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, node := range nodes {
+		node := node
+		eg.Go(func() error {
+			client, err := ssh.GetHostSSHClient(node)
+			if err != nil {
+				return fmt.Errorf("failed to get ssh client for %s: %v", node, err)
+			}
+			cmd := fmt.Sprintf(RemoteJoinAgent, master0, nodeToken)
+			if err := client.CmdAsync(node, cmd); err != nil {
+				return fmt.Errorf("failed to join %s as k3s agent: %v", node, err)
+			}
+			logrus.Infof("Succeeded in joining %s as k3s agent", node)
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// DeleteMasters removes k3s from each given master, then rewrites every agent's lvscare
+// static pod so it stops routing to the masters that just left.
+func (k *Runtime) DeleteMasters(masters []net.IP) error {
+	if err := k.resetHosts(masters); err != nil {
+		return err
+	}
+	return k.updateLoadBalancer()
+}
+
+// DeleteNodes removes k3s from each given node.
+func (k *Runtime) DeleteNodes(nodes []net.IP) error {
+	return k.resetHosts(nodes)
+}
+
+// Reset tears k3s down on every master and node in the cluster.
+func (k *Runtime) Reset() error {
+	hosts := append(append([]net.IP{}, k.cluster.GetMasterIPList()...), k.cluster.GetNodeIPList()...)
+	return k.resetHosts(hosts)
+}
+
+func (k *Runtime) resetHosts(hosts []net.IP) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+	eg, _ := errgroup.WithContext(context.Background())
+	for _, host := range hosts {
+		host := host
+		eg.Go(func() error {
+			client, err := ssh.GetHostSSHClient(host)
+			if err != nil {
+				logrus.Errorf("failed to get ssh client for %s: %v", host, err)
+				return nil
+			}
+			if err := client.CmdAsync(host, RemoteReset); err != nil {
+				logrus.Errorf("failed to reset k3s on %s: %v", host, err)
+			}
+			return nil
+		})
+	}
+	return eg.Wait()
+}
+
+// nodeToken fetches the shared agent join token from master0's on-disk state.
+func (k *Runtime) nodeToken(master0 net.IP) (string, error) {
+	client, err := ssh.GetHostSSHClient(master0)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ssh client for master0(%s): %v", master0, err)
+	}
+	out, err := client.Cmd(master0, RemoteGetNodeToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to read node-token from master0(%s): %v", master0, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// syncKubeconfig copies /etc/rancher/k3s/k3s.yaml from master to ~/.kube/config locally,
+// rewriting the loopback address k3s writes by default to the cluster's VIP so the resulting
+// kubeconfig is usable off-host.
+func (k *Runtime) syncKubeconfig(master net.IP) error {
+	client, err := ssh.GetHostSSHClient(master)
+	if err != nil {
+		return err
+	}
+	raw, err := client.Cmd(master, fmt.Sprintf("cat %s", k3sKubeconfigPath))
+	if err != nil {
+		return fmt.Errorf("failed to read k3s kubeconfig from %s: %v", master, err)
+	}
+	rewritten := strings.ReplaceAll(string(raw), "127.0.0.1", k.tlsSAN())
+	return ssh.WriteLocalKubeConfig(rewritten)
+}
+
+func (k *Runtime) tlsSAN() string {
+	if k.vip != nil {
+		return k.vip.String()
+	}
+	return k.cluster.GetMaster0IP().String()
+}
+
+// PodManifestPath is where lvscare (and any other static pod sealer manages) must be written
+// for a k3s agent, replacing kubeadm's /etc/kubernetes/manifests.
+func PodManifestPath() string {
+	return k3sPodManifestPath
+}
+
+// WriteStaticPod installs yaml as a static pod manifest named name on host, under
+// PodManifestPath(), the k3s equivalent of kubernetes.Runtime writing lvscare's static pod to
+// /etc/kubernetes/manifests.
+func (k *Runtime) WriteStaticPod(host net.IP, name, yaml string) error {
+	client, err := ssh.GetHostSSHClient(host)
+	if err != nil {
+		return fmt.Errorf("failed to get ssh client for %s: %v", host, err)
+	}
+	cmd := fmt.Sprintf("mkdir -p %s && echo '%s' > %s/%s.yaml", PodManifestPath(), yaml, PodManifestPath(), name)
+	if err := client.CmdAsync(host, cmd); err != nil {
+		return fmt.Errorf("failed to write static pod %s on %s: %v", name, host, err)
+	}
+	return nil
+}
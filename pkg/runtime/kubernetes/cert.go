@@ -0,0 +1,186 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/pkg/errors"
+	"github.com/sealerio/sealer/utils/yaml"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RemoteUpdateCerts         = `kubeadm init phase certs apiserver --config=%s/etc/kubeadm.yml`
+	RemoteUpdateKubeletCerts  = `kubeadm init phase certs apiserver-kubelet-client --config=%s/etc/kubeadm.yml`
+	RemoteRestartAPIServerPod = `mv /etc/kubernetes/manifests/kube-apiserver.yaml /tmp/kube-apiserver.yaml && sleep 3 && mv /tmp/kube-apiserver.yaml /etc/kubernetes/manifests/kube-apiserver.yaml`
+)
+
+// validateAltName makes sure name parses cleanly as either an IP address or a DNS name before
+// it's ever shipped to a node, so a typo fails fast instead of being baked into a kubeadm
+// config that then fails obscurely on a remote master.
+func validateAltName(name string) error {
+	if net.ParseIP(name) != nil {
+		return nil
+	}
+	// a DNS name: kubeadm accepts RFC 1123 subdomains, the same rule Kubernetes uses for
+	// most object names, so reuse that shape here rather than re-deriving it.
+	if len(name) == 0 || len(name) > 253 {
+		return fmt.Errorf("invalid SAN %q: must be an IP address or a DNS name of 1-253 characters", name)
+	}
+	for _, label := range splitDNSLabels(name) {
+		if !isValidDNSLabel(label) {
+			return fmt.Errorf("invalid SAN %q: %q is not a valid DNS label", name, label)
+		}
+	}
+	return nil
+}
+
+func splitDNSLabels(name string) []string {
+	var labels []string
+	start := 0
+	for i := 0; i < len(name); i++ {
+		if name[i] == '.' {
+			labels = append(labels, name[start:i])
+			start = i + 1
+		}
+	}
+	labels = append(labels, name[start:])
+	return labels
+}
+
+func isValidDNSLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 {
+		return false
+	}
+	for i := 0; i < len(label); i++ {
+		c := label[i]
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		isDash := c == '-'
+		if !isAlnum && !isDash {
+			return false
+		}
+		if isDash && (i == 0 || i == len(label)-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeAltNames returns the union of the cluster's existing cert SANs and altNames, preserving
+// the existing order and only appending names that aren't already present.
+func mergeAltNames(existing, altNames []string) []string {
+	seen := make(map[string]bool, len(existing))
+	for _, n := range existing {
+		seen[n] = true
+	}
+	merged := append([]string{}, existing...)
+	for _, n := range altNames {
+		if !seen[n] {
+			merged = append(merged, n)
+			seen[n] = true
+		}
+	}
+	return merged
+}
+
+// setCertSANS mirrors getCertSANS, replacing the apiserver cert SANs rendered into kubeadm.yml
+// by MergeKubeadmConfig so that the next `kubeadm init phase certs apiserver` picks them up.
+func (k *Runtime) setCertSANS(sans []string) {
+	k.APIServer.CertSANs = sans
+}
+
+// pushUpdatedKubeadmConfig renders the in-memory kubeadm config -- already carrying the
+// merged SANs setCertSANS just set -- and writes it to host's %s/etc/kubeadm.yml, the same
+// explicit render-and-push step joinMasters performs via sendJoinCPConfig before running any
+// remote kubeadm command that reads that file. Without this, RemoteUpdateCerts below would
+// regenerate the apiserver cert against whatever stale SANs are still on disk.
+func (k *Runtime) pushUpdatedKubeadmConfig(host net.IP) error {
+	cfg, err := yaml.MarshalWithDelimiter(k.InitConfiguration, k.ClusterConfiguration, k.KubeletConfiguration)
+	if err != nil {
+		return fmt.Errorf("failed to render kubeadm config for %s: %v", host, err)
+	}
+	cmd := fmt.Sprintf(RemoteJoinMasterConfig, cfg, k.getRootfs())
+	client, err := k.getHostSSHClient(host)
+	if err != nil {
+		return err
+	}
+	if err := client.CmdAsync(host, cmd); err != nil {
+		return fmt.Errorf("failed to push updated kubeadm config to %s: %v", host, err)
+	}
+	return nil
+}
+
+// UpdateCert regenerates the apiserver and apiserver-kubelet-client certificates on every
+// master with the union of their existing SANs and altNames, restarts kube-apiserver static
+// pods to pick them up, and re-copies /etc/kubernetes/admin.conf. It records the extended SAN
+// list on the Runtime's in-memory kubeadm config only; saving the Clusterfile is left to the
+// caller.
+func (k *Runtime) UpdateCert(altNames []string) error {
+	for _, name := range altNames {
+		if err := validateAltName(name); err != nil {
+			return err
+		}
+	}
+
+	masters := k.cluster.GetMasterIPList()
+	if len(masters) == 0 {
+		return errors.New("no master in cluster, nothing to update certs on")
+	}
+
+	if err := k.WaitSSHReady(6, masters...); err != nil {
+		return errors.Wrap(err, "update cert wait for ssh ready time out")
+	}
+
+	// MergeKubeadmConfig (re)loads and renders the kubeadm config used below, the same
+	// config joinMasters relies on when (re)issuing certs for a new master.
+	if err := k.MergeKubeadmConfig(); err != nil {
+		return err
+	}
+
+	// setCertSANS updates the in-memory kubeadm config/cluster spec; the cmd layer is
+	// responsible for saving the Clusterfile afterwards, same as every other command that
+	// mutates cluster state in place (join, scale, ...).
+	mergedSANs := mergeAltNames(k.getCertSANS(), altNames)
+	k.setCertSANS(mergedSANs)
+
+	for _, master := range masters {
+		logrus.Infof("Start to renew certs on %s with SANs %v", master, mergedSANs)
+
+		if err := k.pushUpdatedKubeadmConfig(master); err != nil {
+			return err
+		}
+
+		client, err := k.getHostSSHClient(master)
+		if err != nil {
+			return err
+		}
+
+		cmds := []string{
+			fmt.Sprintf(RemoteUpdateCerts, k.getRootfs()),
+			fmt.Sprintf(RemoteUpdateKubeletCerts, k.getRootfs()),
+			RemoteRestartAPIServerPod,
+			RemoteCopyKubeConfig,
+		}
+		if err := client.CmdAsync(master, cmds...); err != nil {
+			return fmt.Errorf("failed to renew certs on master(%s): %v", master, err)
+		}
+
+		logrus.Infof("Succeeded in renewing certs on %s", master)
+	}
+
+	return k.sendNewCertAndKey(masters)
+}
@@ -26,9 +26,10 @@ import (
 	"github.com/sealerio/sealer/pkg/clustercert"
 	"github.com/sealerio/sealer/pkg/ipvs"
 	"github.com/sealerio/sealer/pkg/runtime"
+	"github.com/sealerio/sealer/pkg/runtime/bootstrapper"
+	kubeadmboot "github.com/sealerio/sealer/pkg/runtime/bootstrapper/kubeadm"
 	utilsnet "github.com/sealerio/sealer/utils/net"
 	"github.com/sealerio/sealer/utils/ssh"
-	versionUtils "github.com/sealerio/sealer/utils/version"
 	"github.com/sealerio/sealer/utils/yaml"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -41,7 +42,6 @@ import (
 const (
 	V1991 = "v1.19.1"
 	V1992 = "v1.19.2"
-	V1150 = "v1.15.0"
 	V1200 = "v1.20.0"
 	V1230 = "v1.23.0"
 )
@@ -53,12 +53,6 @@ const (
 	RemoteNonRootCopyKubeConfig = `rm -rf ${HOME}/.kube/config && mkdir -p ${HOME}/.kube && cp /etc/kubernetes/admin.conf ${HOME}/.kube/config && chown $(id -u):$(id -g) ${HOME}/.kube/config`
 	RemoteReplaceKubeConfig     = `grep -qF "apiserver.cluster.local" %s  && sed -i 's/apiserver.cluster.local/%s/' %s && sed -i 's/apiserver.cluster.local/%s/' %s`
 	RemoteJoinMasterConfig      = `echo "%s" > %s/etc/kubeadm.yml`
-	InitMaster115Lower          = `kubeadm init --config=%s/etc/kubeadm.yml --experimental-upload-certs`
-	JoinMaster115Lower          = "kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash %s --experimental-control-plane --certificate-key %s"
-	JoinNode115Lower            = "kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash %s"
-	InitMaser115Upper           = `kubeadm init --config=%s/etc/kubeadm.yml --upload-certs`
-	JoinMaster115Upper          = "kubeadm join --config=%s/etc/kubeadm.yml"
-	JoinNode115Upper            = "kubeadm join --config=%s/etc/kubeadm.yml"
 	RemoveKubeConfig            = "rm -rf /usr/bin/kube* && rm -rf ~/.kube/"
 	RemoteCleanMasterOrNode     = `if which kubeadm;then kubeadm reset -f %s;fi && \
 modprobe -r ipip  && lsmod && \
@@ -270,42 +264,57 @@ func vlogToStr(vlog int) string {
 	return " -v " + str
 }
 
+// DefaultBootstrapperName is the bootstrapper.Get name Command uses when SetBootstrapper
+// hasn't been called for this process, preserving the kubeadm-only behavior Runtime has
+// always had.
+const DefaultBootstrapperName = "kubeadm"
+
+// bootstrapperName is the bootstrapper.Get name Command and GetJoinTokenHashAndKey build
+// against. It is process-wide rather than a Runtime field, and only SetBootstrapper changes it;
+// nothing reads it from the Clusterfile. A downstream bootstrapper only needs to call
+// bootstrapper.Register in its own init() and then SetBootstrapper(name) -- no change to this
+// package required.
+var bootstrapperName = DefaultBootstrapperName
+
+// SetBootstrapper selects which registered bootstrapper.Bootstrapper implementation Command
+// builds. Call it once, before rendering any init/join commands.
+func SetBootstrapper(name string) {
+	bootstrapperName = name
+}
+
+// Command renders the init/join command for name against version, delegating the actual
+// version-branch logic (pre- vs post-1.15 kubeadm, or whatever bootstrapperName resolves to)
+// to the selected bootstrapper.Bootstrapper.
 func (k *Runtime) Command(version string, name CommandType) (cmd string) {
-	//cmds := make(map[CommandType]string)
-	// Please convert your v1beta1 configuration files to v1beta2 using the
-	// "kubeadm config migrate" command of kubeadm v1.15.x, so v1.14 not support multi network interface.
-	cmds := map[CommandType]string{
-		InitMaster: fmt.Sprintf(InitMaster115Lower, k.getRootfs()),
-		JoinMaster: fmt.Sprintf(JoinMaster115Lower, k.cluster.GetMaster0IP(), k.getJoinToken(), k.getTokenCaCertHash(), k.getCertificateKey()),
-		JoinNode:   fmt.Sprintf(JoinNode115Lower, k.getVIP(), k.getJoinToken(), k.getTokenCaCertHash()),
-	}
-
-	kv := versionUtils.Version(version)
-	cmp, err := kv.Compare(V1150)
-	//other version >= 1.15.x
+	b, err := bootstrapper.Get(bootstrapperName, bootstrapper.Config{
+		Rootfs:      k.getRootfs(),
+		KubeVersion: version,
+		Vlog:        k.Vlog,
+		InContainer: runtime.IsInContainer(),
+		Master0IP:   k.cluster.GetMaster0IP(),
+		VIP:         k.getVIP(),
+	})
 	if err != nil {
-		logrus.Errorf("failed to compare Kubernetes version: %s", err)
-	}
-	if cmp {
-		cmds[InitMaster] = fmt.Sprintf(InitMaser115Upper, k.getRootfs())
-		cmds[JoinMaster] = fmt.Sprintf(JoinMaster115Upper, k.getRootfs())
-		cmds[JoinNode] = fmt.Sprintf(JoinNode115Upper, k.getRootfs())
-	}
-
-	v, ok := cmds[name]
-	if !ok {
-		logrus.Errorf("failed to get kubeadm command: %v", cmds)
+		logrus.Errorf("failed to build bootstrapper: %v", err)
 		return ""
 	}
 
-	if runtime.IsInContainer() {
-		return fmt.Sprintf("%s%s%s", v, vlogToStr(k.Vlog), " --ignore-preflight-errors=all")
-	}
-	if name == InitMaster || name == JoinMaster {
-		return fmt.Sprintf("%s%s%s", v, vlogToStr(k.Vlog), " --ignore-preflight-errors=SystemVerification")
+	kb, ok := b.(*kubeadmboot.Bootstrapper)
+	if ok {
+		kb.SetJoinCredentials(k.getJoinToken(), k.getTokenCaCertHash(), k.getCertificateKey())
 	}
 
-	return fmt.Sprintf("%s%s", v, vlogToStr(k.Vlog))
+	switch name {
+	case InitMaster:
+		return b.InitCommand()
+	case JoinMaster:
+		return b.JoinMasterCommand()
+	case JoinNode:
+		return b.JoinNodeCommand()
+	default:
+		logrus.Errorf("failed to get bootstrapper command: unknown command type %q", name)
+		return ""
+	}
 }
 
 func (k *Runtime) joinMasters(masters []net.IP) error {
@@ -483,37 +492,44 @@ func (k *Runtime) deleteMaster(master net.IP) error {
 	return eg.Wait()
 }
 
+// GetJoinTokenHashAndKey fetches a fresh certificate key and join token/CA hash for other
+// nodes to join with, through whichever Bootstrapper bootstrapperName selects -- the same
+// indirection Command already goes through -- instead of running kubeadm's upload-certs/
+// token-create commands directly, so a non-kubeadm bootstrapper can supply its own join
+// credentials here too.
 func (k *Runtime) GetJoinTokenHashAndKey() error {
-	cmd := fmt.Sprintf(`kubeadm init phase upload-certs --upload-certs -v %d`, k.Vlog)
-	/*
-		I0415 11:45:06.653868   14520 version.go:251] remote version is much newer: v1.21.0; falling back to: stable-1.16
-		[upload-certs] Storing the certificates in Secret "kubeadm-certs" in the "kube-system" Namespace
-		[upload-certs] Using certificate key:
-		8376c70aaaf285b764b3c1a588740728aff493d7c2239684e84a7367c6a437cf
-	*/
-	output, err := k.CmdToString(k.cluster.GetMaster0IP(), cmd, "\r\n")
+	b, err := bootstrapper.Get(bootstrapperName, bootstrapper.Config{
+		Rootfs:      k.getRootfs(),
+		KubeVersion: k.getKubeVersion(),
+		Vlog:        k.Vlog,
+		InContainer: runtime.IsInContainer(),
+		Master0IP:   k.cluster.GetMaster0IP(),
+		VIP:         k.getVIP(),
+	})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to build bootstrapper: %v", err)
 	}
-	logrus.Debugf("[globals]decodeCertCmd: %s", output)
-	slice := strings.Split(output, "Using certificate key:")
-	if len(slice) != 2 {
-		return fmt.Errorf("failed to get certifacate key: %s", slice)
+
+	master0 := k.cluster.GetMaster0IP()
+	run := func(ip net.IP, cmd string) (string, error) {
+		return k.CmdToString(ip, cmd, "\r\n")
 	}
-	key := strings.Replace(slice[1], "\r\n", "", -1)
-	k.CertificateKey = strings.Replace(key, "\n", "", -1)
-	cmd = fmt.Sprintf("kubeadm token create --print-join-command -v %d", k.Vlog)
 
-	ssh, err := k.getHostSSHClient(k.cluster.GetMaster0IP())
+	certKey, err := b.UploadCerts(run, master0)
 	if err != nil {
-		return fmt.Errorf("failed to get join token hash and key: %v", err)
+		return fmt.Errorf("failed to upload certs: %v", err)
 	}
-	out, err := ssh.Cmd(k.cluster.GetMaster0IP(), cmd)
+	k.CertificateKey = certKey
+
+	token, caHash, err := b.CreateJoinToken(run, master0)
 	if err != nil {
-		return fmt.Errorf("failed to create kubeadm join token: %v", err)
+		return err
 	}
-
-	k.decodeMaster0Output(out)
+	// decodeMaster0Output still owns parsing the join token/hash into whatever the rest of
+	// Runtime reads them from; feed it the same "kubeadm join ..." shape it always has,
+	// reconstructed from what the bootstrapper handed back instead of raw kubeadm stdout.
+	joinCmd := fmt.Sprintf("kubeadm join %s:6443 --token %s --discovery-token-ca-cert-hash %s", master0, token, caHash)
+	k.decodeMaster0Output([]byte(joinCmd))
 
 	return nil
 }
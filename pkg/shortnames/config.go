@@ -0,0 +1,102 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shortnames resolves short, unqualified image references (e.g. "kubernetes:v1.19.8")
+// against a configured set of aliases and a trusted-registry policy, mirroring the
+// containers/image short-name subsystem that podman/buildah already expose to their users.
+package shortnames
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+)
+
+// Mode controls what happens when a short name has no matching alias.
+type Mode string
+
+const (
+	// ModeEnforcing refuses to resolve any short name that isn't aliased.
+	ModeEnforcing Mode = "enforcing"
+	// ModePermissive prompts the user (when attached to a TTY) to pick a registry from
+	// the configured search list, and offers to persist the choice as a new alias.
+	ModePermissive Mode = "permissive"
+)
+
+// DefaultConfigPath is where sealer looks for the short-name alias configuration.
+func DefaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.Getenv("HOME")
+	}
+	return filepath.Join(home, ".sealer", "shortnames.conf")
+}
+
+// Config is the parsed contents of shortnames.conf.
+type Config struct {
+	Mode    Mode              `toml:"mode"`
+	Search  []string          `toml:"search"`
+	Aliases map[string]string `toml:"aliases"`
+}
+
+// LoadConfig reads and parses the short-name configuration at path. A missing file is not an
+// error: it's treated as an empty, permissive configuration with no aliases.
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Mode: ModePermissive, Aliases: map[string]string{}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, errors.Wrapf(err, "failed to read short-name config %s", path)
+	}
+
+	if _, err := toml.Decode(string(data), cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse short-name config %s", path)
+	}
+	if cfg.Aliases == nil {
+		cfg.Aliases = map[string]string{}
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = ModePermissive
+	}
+	return cfg, nil
+}
+
+// Save persists cfg back to path, creating the parent directory if needed.
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return errors.Wrapf(err, "failed to create directory for %s", path)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %s for writing", path)
+	}
+	defer f.Close()
+
+	return toml.NewEncoder(f).Encode(c)
+}
+
+// SetAlias records fqName as the resolution for shortName and persists the change.
+func (c *Config) SetAlias(path, shortName, fqName string) error {
+	if c.Aliases == nil {
+		c.Aliases = map[string]string{}
+	}
+	c.Aliases[shortName] = fqName
+	return c.Save(path)
+}
@@ -0,0 +1,93 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shortnames
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+)
+
+// IsShort reports whether ref looks like a short, unqualified image name rather than a
+// fully-qualified one: no registry domain (no dot/colon-port/"localhost" before the first "/").
+func IsShort(ref string) bool {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) == 1 {
+		return true
+	}
+	host := parts[0]
+	return !strings.ContainsAny(host, ".:") && host != "localhost"
+}
+
+// Resolve turns a possibly-short image reference into a fully-qualified one, consulting the
+// alias table at path and, in permissive mode with a TTY attached, prompting the user to pick
+// a registry from cfg.Search and optionally persisting that choice as a new alias.
+func Resolve(path string, ref string) (string, error) {
+	if !IsShort(ref) {
+		return ref, nil
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return "", err
+	}
+
+	if fq, ok := cfg.Aliases[ref]; ok {
+		return fq, nil
+	}
+
+	if cfg.Mode == ModeEnforcing {
+		return "", errors.Errorf("short-name %q has no alias and mode is %q: refusing to guess a registry", ref, ModeEnforcing)
+	}
+
+	if len(cfg.Search) == 0 || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.Errorf("short-name %q has no alias, and either no search registries are configured or no terminal is attached to pick one; add an alias to %s", ref, path)
+	}
+
+	fq, err := promptForRegistry(ref, cfg.Search)
+	if err != nil {
+		return "", err
+	}
+
+	if err := cfg.SetAlias(path, ref, fq); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to persist alias for %q: %v\n", ref, err)
+	}
+	return fq, nil
+}
+
+func promptForRegistry(shortName string, search []string) (string, error) {
+	fmt.Printf("%q is a short name; which registry should it resolve to?\n", shortName)
+	for i, reg := range search {
+		fmt.Printf("  %d) %s/%s\n", i+1, reg, shortName)
+	}
+	fmt.Print("Enter a number, or press Enter for 1: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	line, _ := reader.ReadString('\n')
+	line = strings.TrimSpace(line)
+
+	choice := 1
+	if line != "" {
+		if _, err := fmt.Sscanf(line, "%d", &choice); err != nil || choice < 1 || choice > len(search) {
+			return "", errors.Errorf("invalid selection %q", line)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s", search[choice-1], shortName), nil
+}
@@ -0,0 +1,249 @@
+// Copyright © 2022 Alibaba Group Holding Ltd.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sign signs and verifies ClusterImages using cosign's "simple signing" layout: a
+// detached signature over a small JSON payload naming the image reference and manifest
+// digest, stored as an OCI artifact tagged "sha256-<digest>.sig" in the same repository as
+// the image. Only key-pair (ECDSA) signing is implemented; keyless (Fulcio/Rekor) signing is
+// out of scope here and callers should reject --keyless explicitly rather than silently
+// falling back to an unsigned push.
+package sign
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/distribution/distribution/v3"
+	"github.com/distribution/distribution/v3/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+)
+
+// SimpleSigningMediaType is the media type cosign stores a simple-signing payload layer under.
+const SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+
+// signatureAnnotation is the manifest-layer annotation cosign stores the base64 signature in.
+const signatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// simpleSigningPayload is cosign's "simple signing" envelope: a minimal statement that ref's
+// manifest digest is the one being attested to.
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// SignatureTag is the tag a detached signature for dgst is stored under, e.g.
+// "sha256-abcd1234....sig".
+func SignatureTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Encoded())
+}
+
+func newPayload(ref string, dgst digest.Digest) ([]byte, error) {
+	var p simpleSigningPayload
+	p.Critical.Identity.DockerReference = ref
+	p.Critical.Image.DockerManifestDigest = dgst.String()
+	p.Critical.Type = "cosign container image signature"
+	return json.Marshal(p)
+}
+
+func loadECDSAPrivateKey(path string) (*ecdsa.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %v", path, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from %s", path)
+	}
+	key, err := x509.ParseECPrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse EC private key %s: %v", path, err)
+	}
+	return key, nil
+}
+
+func loadECDSAPublicKey(path string) (*ecdsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read verification key %s: %v", path, err)
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM block from %s", path)
+	}
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %v", path, err)
+	}
+	ecKey, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an ECDSA public key", path)
+	}
+	return ecKey, nil
+}
+
+// Sign computes a detached signature over ref's manifest digest using the EC private key at
+// keyPath and uploads it into repo as an OCI artifact tagged SignatureTag(dgst).
+func Sign(ctx context.Context, repo distribution.Repository, ref string, dgst digest.Digest, keyPath string) error {
+	key, err := loadECDSAPrivateKey(keyPath)
+	if err != nil {
+		return err
+	}
+
+	payload, err := newPayload(ref, dgst)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, key, sum[:])
+	if err != nil {
+		return fmt.Errorf("failed to sign %s: %v", ref, err)
+	}
+
+	return upload(ctx, repo, dgst, payload, sig)
+}
+
+func upload(ctx context.Context, repo distribution.Repository, dgst digest.Digest, payload, sig []byte) error {
+	blobs, err := repo.Blobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	configDesc, err := blobs.Put(ctx, schema2.MediaTypeImageConfig, []byte("{}"))
+	if err != nil {
+		return fmt.Errorf("failed to push signature config blob: %v", err)
+	}
+
+	layerDesc, err := blobs.Put(ctx, SimpleSigningMediaType, payload)
+	if err != nil {
+		return fmt.Errorf("failed to push signature payload blob: %v", err)
+	}
+	layerDesc.Annotations = map[string]string{
+		signatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+	}
+
+	mfst, err := schema2.FromStruct(schema2.Manifest{
+		Versioned: schema2.SchemaVersion,
+		Config:    configDesc,
+		Layers:    []distribution.Descriptor{layerDesc},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build signature manifest: %v", err)
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = manifests.Put(ctx, mfst, distribution.WithTag(SignatureTag(dgst)))
+	if err != nil {
+		return fmt.Errorf("failed to push signature manifest: %v", err)
+	}
+	return nil
+}
+
+// Verify fetches the detached signature for dgst from repo and checks it against the EC
+// public key at pubKeyPath and ref, returning an error if no signature exists, it was signed
+// for a different reference, or verification fails.
+func Verify(ctx context.Context, repo distribution.Repository, ref string, dgst digest.Digest, pubKeyPath string) error {
+	pub, err := loadECDSAPublicKey(pubKeyPath)
+	if err != nil {
+		return err
+	}
+
+	sigDesc, err := repo.Tags(ctx).Get(ctx, SignatureTag(dgst))
+	if err != nil {
+		return fmt.Errorf("no signature found for %s: %v", dgst, err)
+	}
+
+	manifests, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	m, err := manifests.Get(ctx, sigDesc.Digest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature manifest for %s: %v", dgst, err)
+	}
+	sm, ok := m.(*schema2.DeserializedManifest)
+	if !ok || len(sm.Layers) == 0 {
+		return fmt.Errorf("signature manifest for %s is malformed", dgst)
+	}
+
+	layer := sm.Layers[0]
+	sigB64, ok := layer.Annotations[signatureAnnotation]
+	if !ok {
+		return fmt.Errorf("signature manifest for %s is missing its signature annotation", dgst)
+	}
+	sig, err := base64.StdEncoding.DecodeString(sigB64)
+	if err != nil {
+		return fmt.Errorf("signature manifest for %s has a malformed signature: %v", dgst, err)
+	}
+
+	blobs, err := repo.Blobs(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := blobs.Get(ctx, layer.Digest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch signature payload for %s: %v", dgst, err)
+	}
+
+	var p simpleSigningPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid signature payload for %s: %v", dgst, err)
+	}
+	if p.Critical.Image.DockerManifestDigest != dgst.String() {
+		return fmt.Errorf("signature payload digest %s does not match %s", p.Critical.Image.DockerManifestDigest, dgst)
+	}
+	if p.Critical.Identity.DockerReference != ref {
+		return fmt.Errorf("signature payload reference %s does not match %s", p.Critical.Identity.DockerReference, ref)
+	}
+
+	sum := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(pub, sum[:], sig) {
+		return fmt.Errorf("signature verification failed for %s", dgst)
+	}
+	return nil
+}
+
+// EnforcePolicy is Verify gated behind a required flag, so every place a ClusterImage gets
+// pulled can apply the same --verify-signatures policy with one call instead of duplicating
+// the "is this even turned on, is a key configured" checks: a no-op when required is false,
+// otherwise a hard failure with no pubKeyPath and Verify's result otherwise. Today that's just
+// the standalone `sealer pull` command; a ClusterImage pull folded into `sealer run`/`sealer
+// join` cluster bring-up should call this too once that path exists in this tree.
+func EnforcePolicy(ctx context.Context, repo distribution.Repository, ref string, dgst digest.Digest, pubKeyPath string, required bool) error {
+	if !required {
+		return nil
+	}
+	if pubKeyPath == "" {
+		return fmt.Errorf("signature verification is required but no verification key was provided")
+	}
+	return Verify(ctx, repo, ref, dgst, pubKeyPath)
+}